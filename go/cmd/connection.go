@@ -0,0 +1,182 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+	"vitess.io/vitess/go/mysql"
+)
+
+// passwordEnv is the environment variable the MySQL clients read the password from
+const passwordEnv = "MYSQL_PWD"
+
+// connectionFlags are the flags of the commands that connect to MySQL
+type connectionFlags struct {
+	params       mysql.ConnParams
+	defaultsFile string
+	askPassword  bool
+}
+
+func addConnectionFlags(cmd *cobra.Command, c *connectionFlags) {
+	cmd.Flags().StringVar(&c.params.Host, "host", "127.0.0.1", "Database host")
+	cmd.Flags().IntVar(&c.params.Port, "port", 3306, "Database port")
+	cmd.Flags().StringVar(&c.params.Uname, "user", "root", "Database user")
+	cmd.Flags().StringVar(&c.params.Pass, "password", "", "Database password, prefer --ask-password or the "+passwordEnv+" environment variable")
+	cmd.Flags().BoolVar(&c.askPassword, "ask-password", false, "Prompt for the database password")
+	cmd.Flags().StringVar(&c.params.UnixSocket, "socket", "", "Unix socket to connect to instead of host and port")
+	cmd.Flags().StringVar(&c.defaultsFile, "defaults-file", "", "MySQL option file to read the [client] connection options from, the flags take precedence")
+	cmd.Flags().Var(&c.params.SslMode, "ssl-mode", "TLS mode: disabled, preferred, required, verify_ca or verify_identity")
+	cmd.Flags().StringVar(&c.params.SslCa, "ssl-ca", "", "File with the certificate authority to verify the server certificate with")
+	cmd.Flags().StringVar(&c.params.SslCert, "ssl-cert", "", "File with the client certificate")
+	cmd.Flags().StringVar(&c.params.SslKey, "ssl-key", "", "File with the key of the client certificate")
+	cmd.Flags().StringVar(&c.params.ServerName, "ssl-server-name", "", "Server name to verify the server certificate against, defaults to the host")
+}
+
+// connParams resolves the connection parameters. The flags win over the defaults file, and the password
+// is taken from --password, the prompt, the defaults file and the MYSQL_PWD environment variable, in that order.
+func (c *connectionFlags) connParams(cmd *cobra.Command) (mysql.ConnParams, error) {
+	params := c.params
+	if c.defaultsFile != "" {
+		options, err := readDefaultsFile(c.defaultsFile)
+		if err != nil {
+			return params, err
+		}
+		if err := applyDefaults(cmd, &params, options); err != nil {
+			return params, fmt.Errorf("%s: %w", c.defaultsFile, err)
+		}
+	}
+
+	switch {
+	case cmd.Flags().Changed("password"):
+	case c.askPassword:
+		password, err := promptPassword()
+		if err != nil {
+			return params, err
+		}
+		params.Pass = password
+	case params.Pass == "":
+		params.Pass = os.Getenv(passwordEnv)
+	}
+	return params, nil
+}
+
+// applyDefaults sets the connection parameters from the options of a defaults file,
+// except for the ones given as flags
+func applyDefaults(cmd *cobra.Command, params *mysql.ConnParams, options map[string]string) error {
+	fields := map[string]*string{
+		"host":            &params.Host,
+		"user":            &params.Uname,
+		"password":        &params.Pass,
+		"socket":          &params.UnixSocket,
+		"ssl-ca":          &params.SslCa,
+		"ssl-cert":        &params.SslCert,
+		"ssl-key":         &params.SslKey,
+		"ssl-server-name": &params.ServerName,
+		"database":        &params.DbName,
+	}
+	for option, value := range options {
+		// the options have the names of the flags
+		if cmd.Flags().Changed(option) {
+			continue
+		}
+		switch option {
+		case "port":
+			port, err := strconv.Atoi(value)
+			if err != nil {
+				return fmt.Errorf("invalid port %q", value)
+			}
+			params.Port = port
+		case "ssl-mode":
+			if err := params.SslMode.Set(value); err != nil {
+				return err
+			}
+		default:
+			if field, ok := fields[option]; ok {
+				*field = value
+			}
+		}
+	}
+	return nil
+}
+
+// readDefaultsFile reads the options of the [client] group of a MySQL option file, overridden by the [vt] group.
+// Option names are normalized to use dashes, like the command line options.
+func readDefaultsFile(fileName string) (map[string]string, error) {
+	f, err := os.Open(fileName)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	client := make(map[string]string)
+	vt := make(map[string]string)
+	var group map[string]string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case line == "" || line[0] == '#' || line[0] == ';' || line[0] == '!':
+			continue
+		case line[0] == '[':
+			group = nil
+			switch strings.ToLower(strings.Trim(line, "[] ")) {
+			case "client":
+				group = client
+			case "vt":
+				group = vt
+			}
+			continue
+		case group == nil:
+			continue
+		}
+		name, value, _ := strings.Cut(line, "=")
+		name = strings.ReplaceAll(strings.ToLower(strings.TrimSpace(name)), "_", "-")
+		value = strings.TrimSpace(value)
+		if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+			value = value[1 : len(value)-1]
+		}
+		group[name] = value
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	for name, value := range vt {
+		client[name] = value
+	}
+	return client, nil
+}
+
+func promptPassword() (string, error) {
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		return "", errors.New("--ask-password needs a terminal")
+	}
+	fmt.Fprint(os.Stderr, "Enter password: ")
+	password, err := term.ReadPassword(int(os.Stdin.Fd()))
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return "", err
+	}
+	return string(password), nil
+}
@@ -0,0 +1,80 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"vitess.io/vitess/go/vt/vttls"
+)
+
+func TestConnParams(t *testing.T) {
+	defaultsFile := filepath.Join(t.TempDir(), "my.cnf")
+	err := os.WriteFile(defaultsFile, []byte(`
+# options of the mysql client
+[client]
+host = db.example.com
+port = 3307
+user = "analyst"
+password = secret
+ssl_mode = VERIFY_IDENTITY
+ssl-ca = /etc/mysql/ca.pem
+
+[mysqld]
+port = 3306
+
+[vt]
+user = vt
+`), 0o600)
+	require.NoError(t, err)
+
+	parse := func(args ...string) (*cobra.Command, *connectionFlags) {
+		cmd := &cobra.Command{}
+		var conn connectionFlags
+		addConnectionFlags(cmd, &conn)
+		require.NoError(t, cmd.ParseFlags(args))
+		return cmd, &conn
+	}
+
+	cmd, conn := parse("--defaults-file", defaultsFile, "--host", "127.0.0.1")
+	params, err := conn.connParams(cmd)
+	require.NoError(t, err)
+	assert.Equal(t, "127.0.0.1", params.Host)
+	assert.Equal(t, 3307, params.Port)
+	assert.Equal(t, "vt", params.Uname)
+	assert.Equal(t, "secret", params.Pass)
+	assert.Equal(t, vttls.VerifyIdentity, params.SslMode)
+	assert.Equal(t, "/etc/mysql/ca.pem", params.SslCa)
+
+	t.Setenv(passwordEnv, "from-env")
+	cmd, conn = parse("--socket", "/tmp/mysql.sock", "--ssl-mode", "required")
+	params, err = conn.connParams(cmd)
+	require.NoError(t, err)
+	assert.Equal(t, "from-env", params.Pass)
+	assert.Equal(t, "/tmp/mysql.sock", params.UnixSocket)
+	assert.Equal(t, vttls.Required, params.SslMode)
+
+	cmd, conn = parse("--password", "")
+	params, err = conn.connParams(cmd)
+	require.NoError(t, err)
+	assert.Empty(t, params.Pass)
+}
@@ -17,34 +17,116 @@ limitations under the License.
 package cmd
 
 import (
+	"errors"
+	"time"
+
 	"github.com/spf13/cobra"
-	"vitess.io/vitess/go/mysql"
 
 	"github.com/vitessio/vt/go/dbinfo"
+	"github.com/vitessio/vt/go/keys"
 )
 
 func dbinfoCmd() *cobra.Command {
-	var vtParams mysql.ConnParams
+	var conn connectionFlags
+	var databases []string
+	var allDatabases bool
+	var sampleKeysFile string
+	var sampleColumns int
+	var globalVariables []string
+	var sqlFile, rowCountsFile string
+	var connections int
+	var queryTimeout time.Duration
+	sample := dbinfo.SampleConfig{}
 
 	cmd := &cobra.Command{
 		Use:     "dbinfo ",
 		Short:   "Loads info from the database including row counts",
-		Example: "vt dbinfo",
+		Example: "vt dbinfo --sample keys-log.json\nvt dbinfo --from-sql schema.sql --row-counts rows.csv",
 		Args:    cobra.ExactArgs(0),
-		RunE: func(_ *cobra.Command, _ []string) error {
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			vtParams, err := conn.connParams(cmd)
+			if err != nil {
+				return err
+			}
 			cfg := dbinfo.Config{
-				VTParams: vtParams,
+				VTParams:        vtParams,
+				Databases:       databases,
+				AllDatabases:    allDatabases,
+				GlobalVariables: globalVariables,
+				SQLFile:         sqlFile,
+				RowCountsFile:   rowCountsFile,
+				Connections:     connections,
+				QueryTimeout:    queryTimeout,
+			}
+			if rowCountsFile != "" && sqlFile == "" {
+				return errors.New("--row-counts can only be used with --from-sql")
+			}
+
+			if sampleKeysFile != "" {
+				columns, err := keyColumnsToSample(sampleKeysFile, sampleColumns)
+				if err != nil {
+					return err
+				}
+				sample.Columns = columns
+				cfg.Sample = sample
 			}
 
 			return dbinfo.Run(cfg)
 		},
 	}
 
-	cmd.Flags().StringVarP(&vtParams.Host, "host", "", "127.0.0.1", "Database host")
-	cmd.Flags().IntVarP(&vtParams.Port, "port", "", 3306, "Database port")
-	cmd.Flags().StringVarP(&vtParams.Uname, "user", "", "root", "Database user")
-	cmd.Flags().StringVarP(&vtParams.Pass, "password", "", "", "Database password")
-	cmd.Flags().StringVarP(&vtParams.DbName, "database", "", "", "Database name")
+	addConnectionFlags(cmd, &conn)
+	cmd.Flags().StringSliceVar(&databases, "database", nil, "Database names, comma separated or repeated. Tables are qualified by their database when there is more than one")
+	cmd.Flags().BoolVar(&allDatabases, "all-databases", false, "Load all databases except the system ones, with tables qualified by their database")
+	cmd.Flags().StringSliceVar(&globalVariables, "global-variables", nil,
+		"Global variables to collect on top of the Vitess relevant ones, as names or regular expressions like innodb_.*")
+	cmd.Flags().IntVar(&connections, "connections", dbinfo.DefaultConnections, "Number of connections to load the database with concurrently")
+	cmd.Flags().DurationVar(&queryTimeout, "query-timeout", dbinfo.DefaultQueryTimeout, "How long a query can run before it is killed and what it loads is left out with a warning")
+	cmd.Flags().StringVar(&sqlFile, "from-sql", "", "Schema dump, like mysqldump --no-data output, to read the tables from instead of the database")
+	cmd.Flags().StringVar(&rowCountsFile, "row-counts", "", "CSV file of table names and row counts for the tables of --from-sql")
+	cmd.Flags().StringVar(&sampleKeysFile, "sample", "", "vt keys output whose most used equality filter and join columns have their values sampled")
+	cmd.Flags().IntVar(&sampleColumns, "sample-columns", 20, "Maximum number of columns to sample")
+	cmd.Flags().IntVar(&sample.Rows, "sample-rows", 100000, "Number of rows read from the table for each sampled column")
+	cmd.Flags().IntVar(&sample.TopN, "sample-top", 5, "Number of most frequent values kept for each sampled column")
+
+	cmd.AddCommand(dbinfoDiffCmd())
 
 	return cmd
 }
+
+func dbinfoDiffCmd() *cobra.Command {
+	var cfg dbinfo.DiffConfig
+
+	cmd := &cobra.Command{
+		Use:   "diff old.json new.json",
+		Short: "Reports the schema changes between two dbinfo files",
+		Long: "Reports the tables that were added or removed, the column, index and foreign key changes, " +
+			"and the tables whose row count changed significantly, between two dbinfo files. The output can be summarized with vt summarize.",
+		Example: "vt dbinfo diff dbinfo-before.json dbinfo-after.json > dbinfo-diff.json",
+		Args:    cobra.ExactArgs(2),
+		RunE: func(_ *cobra.Command, args []string) error {
+			cfg.OldFile, cfg.NewFile = args[0], args[1]
+			return dbinfo.RunDiff(cfg)
+		},
+	}
+
+	cmd.Flags().Float64Var(&cfg.MinRowGrowth, "min-row-growth", dbinfo.DefaultMinRowGrowth, "Relative change in row count, like 0.2 for 20%, a table needs to be reported")
+
+	return cmd
+}
+
+// keyColumnsToSample picks the columns of a keys file that are used the most like sharding keys
+func keyColumnsToSample(keysFile string, limit int) ([]dbinfo.SampleColumn, error) {
+	ko, err := keys.ReadKeysFile(keysFile)
+	if err != nil {
+		return nil, err
+	}
+	var columns []dbinfo.SampleColumn
+	for _, col := range ko.KeyColumns() {
+		if len(columns) == limit {
+			break
+		}
+		columns = append(columns, dbinfo.SampleColumn{Table: col.Table, Column: col.Column})
+	}
+	return columns, nil
+}
@@ -94,3 +94,8 @@ func csvFlagsToConfig(cmd *cobra.Command, flags csvFlags) data.CSVConfig {
 	}
 	return c
 }
+
+func addPlannerFlags(cmd *cobra.Command, opts *data.PlannerOptions) {
+	cmd.Flags().StringVar(&opts.MySQLVersion, "mysql-version", "", "MySQL server version to parse and plan queries for, like 8.0.40 or 8.4. Defaults to the version of the bundled Vitess")
+	cmd.Flags().StringVar(&opts.ForeignKeyMode, "foreign-key-mode", "", "Foreign key mode to plan every keyspace with: unmanaged, managed or disallow. Defaults to the mode in the vschema")
+}
@@ -27,6 +27,8 @@ func keysCmd() *cobra.Command {
 	var inputType string
 	flags := new(csvFlags)
 	var csvConfig data.CSVConfig
+	var planner data.PlannerOptions
+	var dbInfoFile string
 	cmd := &cobra.Command{
 		Use:     "keys ",
 		Short:   "Runs vexplain keys on all queries of the test file",
@@ -35,7 +37,9 @@ func keysCmd() *cobra.Command {
 		RunE: func(c *cobra.Command, args []string) error {
 			csvConfig = csvFlagsToConfig(c, *flags)
 			cfg := keys.Config{
-				FileName: args[0],
+				FileName:   args[0],
+				Planner:    planner,
+				DBInfoFile: dbInfoFile,
 			}
 
 			loader, err := configureLoader(inputType, false, csvConfig)
@@ -50,6 +54,8 @@ func keysCmd() *cobra.Command {
 
 	addInputTypeFlag(cmd, &inputType)
 	addCSVConfigFlag(cmd, flags)
+	addPlannerFlags(cmd, &planner)
+	cmd.Flags().StringVar(&dbInfoFile, "dbinfo", "", "Output of vt dbinfo, used as the authoritative column lists of the tables")
 
 	return cmd
 }
@@ -17,27 +17,78 @@ limitations under the License.
 package cmd
 
 import (
+	"errors"
+
 	"github.com/spf13/cobra"
 
+	"github.com/vitessio/vt/go/data"
 	"github.com/vitessio/vt/go/planalyze"
 )
 
 func planalyzeCmd() *cobra.Command {
 	var cfg planalyze.Config
+	var inputType string
+	flags := new(csvFlags)
+	var maxComplex, maxUnplannable float64
+	enableSystemSettings := true
 
 	cmd := &cobra.Command{
-		Use:     "planalyze",
-		Short:   "Analyze the query plans using the keys output",
-		Long:    "Analyze the query plans. The report will report how many queries fall into one of the four categories: `passthrough`, `simple-routed`, `complex`, `unplannable`.",
+		Use:   "planalyze",
+		Short: "Analyze the query plans of the keys output or of a query log",
+		Long: "Analyze the query plans. The report will report how many queries fall into one of the five categories: `passThrough`, `simpleRouted`, `scatter`, `complex`, `unplannable`. " +
+			"Every query carries its usage count, query time and rows examined, which vt summarize uses to weigh the categories by their share of the workload. " +
+			"With --compare-vschema, the queries are planned with both vschemas and the report shows how the plans would change, with the weighted totals of every change of category. " +
+			"With --input-type, the argument is a query log that is analyzed directly instead of the output of `vt keys`. " +
+			"With --baseline or the share thresholds, planalyze runs as a CI gate: it prints the regressions and exits with an error when a check fails.",
 		Example: "vt planalyze --vcshema file.vschema keys-log.json",
 		Args:    cobra.ExactArgs(1),
-		RunE: func(_ *cobra.Command, args []string) error {
-			return planalyze.Run(cfg, args[0])
+		RunE: func(c *cobra.Command, args []string) error {
+			if c.Flags().Changed("input-type") {
+				loader, err := configureLoader(inputType, false, csvFlagsToConfig(c, *flags))
+				if err != nil {
+					return err
+				}
+				if vtgateLoader, ok := loader.(data.VtGateLogLoader); ok {
+					// the logged bind variables are the values the queries are routed with
+					vtgateLoader.KeepBindVars = true
+					loader = vtgateLoader
+				}
+				cfg.Loader = loader
+			}
+			cfg.Planner.DisableSystemSettings = !enableSystemSettings
+			if c.Flags().Changed("max-complex-share") {
+				cfg.Gate.MaxComplexShare = &maxComplex
+			}
+			if c.Flags().Changed("max-unplannable-share") {
+				cfg.Gate.MaxUnplannableShare = &maxUnplannable
+			}
+			err := planalyze.Run(cfg, args[0])
+			if errors.Is(err, planalyze.ErrGateFailed) {
+				c.SilenceUsage = true
+			}
+			return err
 		},
 	}
 
 	cmd.Flags().StringVar(&cfg.VSchemaFile, "vschema", "", "Supply the vschema in a format that can contain multiple keyspaces. This cannot be used with -vtexplain-vschema.")
 	cmd.Flags().StringVar(&cfg.VtExplainVschemaFile, "vtexplain-vschema", "", "Supply the vschema in a format that contains a single keyspace")
 
+	cmd.Flags().StringVar(&cfg.CompareVSchemaFile, "compare-vschema", "", "Plan the queries with this second vschema, in the same format as the first one, and report the differences")
+
+	cmd.Flags().StringVar(&cfg.DBInfoFile, "dbinfo", "", "Output of vt dbinfo, used as the authoritative column lists of the tables")
+	cmd.Flags().StringVar(&cfg.SchemaFile, "schema", "", "SQL schema dump whose CREATE TABLE statements are used as the authoritative column lists of the tables")
+
+	cmd.Flags().StringVar(&cfg.Gate.BaselineFile, "baseline", "", "Previous planalyze output. Fail when a query gets a worse complexity than in the baseline")
+	cmd.Flags().Float64Var(&maxComplex, "max-complex-share", 0, "Fail when more than this percentage of the executions are complex routed")
+	cmd.Flags().Float64Var(&maxUnplannable, "max-unplannable-share", 0, "Fail when more than this percentage of the executions are unplannable")
+
+	addPlannerFlags(cmd, &cfg.Planner)
+	cmd.Flags().BoolVar(&enableSystemSettings, "enable-system-settings", true, "Plan queries that change system settings like vtgate does with --enable_system_settings")
+
+	addInputTypeFlag(cmd, &inputType)
+	addCSVConfigFlag(cmd, flags)
+	cmd.Flags().IntVar(&cfg.Samples, "sample-bind-values", 0, "Route this many executions of every query with their own values and report the shard fan-out. Needs --input-type")
+	cmd.Flags().IntVar(&cfg.Shards, "shards", planalyze.DefaultShards, "Number of shards of the sharded keyspaces, used for the fan-out")
+
 	return cmd
 }
@@ -0,0 +1,52 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/vitessio/vt/go/recommend"
+)
+
+func recommendCmd() *cobra.Command {
+	var cfg recommend.Config
+
+	cmd := &cobra.Command{
+		Use:   "recommend",
+		Short: "Recommend a VSchema for the queries in the keys output",
+		Long: "Search for the sharding keys that give the simplest query plans. Every candidate vindex assignment is planned offline " +
+			"and scored by the complexity of the plans, weighted by the usage of the queries. " +
+			"With a dbinfo file, small tables can be made reference tables; with a transactions file, columns that link the tables of a transaction are preferred. " +
+			"The column lists of the dbinfo file or of a schema dump are used to plan the queries, like vt planalyze does.",
+		Example: "vt recommend --dbinfo dbinfo.json --transactions txs.json --vschema-output vschema.json keys-log.json",
+		Args:    cobra.ExactArgs(1),
+		RunE: func(_ *cobra.Command, args []string) error {
+			cfg.KeysFile = args[0]
+			return recommend.Run(cfg)
+		},
+	}
+
+	cmd.Flags().StringVar(&cfg.DBInfoFile, "dbinfo", "", "Output of vt dbinfo, used for row counts, primary keys and the column lists of the tables")
+	cmd.Flags().StringVar(&cfg.SchemaFile, "schema", "", "SQL schema dump whose CREATE TABLE statements are used as the column lists of the tables")
+	cmd.Flags().StringVar(&cfg.TransactionsFile, "transactions", "", "Output of vt transactions, used to keep tables modified together on the same shard")
+	cmd.Flags().StringVar(&cfg.VSchemaOutput, "vschema-output", "", "Write the recommended vschema to this file")
+	cmd.Flags().IntVar(&cfg.ReferenceRows, "reference-rows", recommend.DefaultReferenceRows, "Tables with at most this many rows can be made reference tables")
+	cmd.Flags().IntVar(&cfg.Candidates, "candidates", recommend.DefaultCandidates, "Number of sharding key candidates to try for each table")
+	addPlannerFlags(cmd, &cfg.Planner)
+
+	return cmd
+}
@@ -46,6 +46,7 @@ func getRootCmd() *cobra.Command {
 	root.AddCommand(dbinfoCmd())
 	root.AddCommand(transactionsCmd())
 	root.AddCommand(planalyzeCmd())
+	root.AddCommand(recommendCmd())
 	root.AddCommand(versionCmd())
 	return root
 }
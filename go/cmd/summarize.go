@@ -17,6 +17,9 @@ limitations under the License.
 package cmd
 
 import (
+	"fmt"
+
+	humanize "github.com/dustin/go-humanize"
 	"github.com/spf13/cobra"
 
 	"github.com/vitessio/vt/go/summarize"
@@ -27,6 +30,7 @@ func summarizeCmd() *cobra.Command {
 	var showGraph bool
 	var outputFormat string
 	var launchWebServer bool
+	var targetShardSize string
 
 	cmd := &cobra.Command{
 		Use:     "summarize old_file.json [new_file.json]",
@@ -34,8 +38,13 @@ func summarizeCmd() *cobra.Command {
 		Short:   "Compares and analyses a trace output",
 		Example: "vt summarize old.json new.json",
 		Args:    cobra.RangeArgs(1, 2),
-		Run: func(_ *cobra.Command, args []string) {
-			summarize.Run(args, hotMetric, showGraph, outputFormat, launchWebServer)
+		RunE: func(_ *cobra.Command, args []string) error {
+			shardSize, err := humanize.ParseBytes(targetShardSize)
+			if err != nil {
+				return fmt.Errorf("invalid target shard size: %w", err)
+			}
+			summarize.Run(args, hotMetric, showGraph, outputFormat, launchWebServer, shardSize)
+			return nil
 		},
 	}
 
@@ -43,5 +52,6 @@ func summarizeCmd() *cobra.Command {
 	cmd.Flags().BoolVar(&showGraph, "graph", false, "Show the query graph in the browser")
 	cmd.Flags().StringVar(&outputFormat, "format", "markdown", "Output format (options: html, markdown)")
 	cmd.Flags().BoolVar(&launchWebServer, "web", false, "Start a web server to view the summary")
+	cmd.Flags().StringVar(&targetShardSize, "target-shard-size", "250GB", "Size of a shard, like 250GB, used to estimate how many shards the tables of a dbinfo file need")
 	return cmd
 }
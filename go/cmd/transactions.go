@@ -25,6 +25,10 @@ import (
 
 func transactionsCmd() *cobra.Command {
 	var inputType string
+	var minCount int
+	var autocommit string
+	var cluster bool
+	var clusterSimilarity float64
 	flags := new(csvFlags)
 	var csvConfig data.CSVConfig
 
@@ -39,13 +43,34 @@ func transactionsCmd() *cobra.Command {
 		},
 		RunE: func(_ *cobra.Command, args []string) error {
 			cfg := transactions.Config{
-				FileName: args[0],
+				FileName:   args[0],
+				MinCount:   minCount,
+				Autocommit: autocommit,
+
+				Cluster:           cluster,
+				ClusterSimilarity: clusterSimilarity,
+			}
+			if err := transactions.ValidateAutocommitMode(autocommit); err != nil {
+				return err
+			}
+			if err := transactions.ValidateClusterSimilarity(clusterSimilarity); err != nil {
+				return err
 			}
 
 			loader, err := configureLoader(inputType, false, csvConfig)
 			if err != nil {
 				return err
 			}
+			if vtgateLoader, ok := loader.(data.VtGateLogLoader); ok {
+				// bind variables let us correlate values across the queries of a transaction
+				vtgateLoader.KeepBindVars = true
+				loader = vtgateLoader
+			}
+			if mysqlLoader, ok := loader.(data.MySQLLogLoader); ok {
+				// Connect, Quit and Change user reset the session state
+				mysqlLoader.ConnectionEvents = true
+				loader = mysqlLoader
+			}
 			cfg.Loader = loader
 
 			transactions.Run(cfg)
@@ -55,6 +80,13 @@ func transactionsCmd() *cobra.Command {
 
 	addInputTypeFlag(cmd, &inputType)
 	addCSVConfigFlag(cmd, flags)
+	cmd.Flags().StringVar(&autocommit, "autocommit", transactions.AutocommitAuto,
+		"Autocommit setting connections start with: on, off or auto to guess it from the log")
+	cmd.Flags().BoolVar(&cluster, "cluster", false,
+		"Collapse repeated statements and group similar transaction patterns into families")
+	cmd.Flags().Float64Var(&clusterSimilarity, "cluster-similarity", transactions.DefaultClusterSimilarity,
+		"How similar, between 0 and 1, a transaction pattern has to be to join a family")
+	cmd.Flags().IntVar(&minCount, "min-count", 2, "Only report transaction patterns seen at least this many times")
 
 	return cmd
 }
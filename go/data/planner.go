@@ -0,0 +1,64 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package data
+
+import (
+	"fmt"
+	"strings"
+
+	vschemapb "vitess.io/vitess/go/vt/proto/vschema"
+	"vitess.io/vitess/go/vt/vtenv"
+)
+
+// PlannerOptions select the vtgate behavior queries are analyzed and planned for,
+// so the results match the versions and settings of the cluster they will run on
+type PlannerOptions struct {
+	// MySQLVersion is the MySQL server version vtgate parses queries for and advertises, like 8.0.40 or 8.4.
+	// Empty means the default of the Vitess version vt is built with.
+	MySQLVersion string
+
+	// ForeignKeyMode replaces the foreign key mode of every keyspace: unmanaged, managed or disallow.
+	// Empty keeps the mode of the vschema, unmanaged when it does not set one.
+	ForeignKeyMode string
+
+	// DisableSystemSettings plans queries that change system settings like vtgate does with --enable_system_settings=false
+	DisableSystemSettings bool
+}
+
+// Environment returns the Vitess environment for the MySQL version
+func (o PlannerOptions) Environment() (*vtenv.Environment, error) {
+	if o.MySQLVersion == "" {
+		return vtenv.NewTestEnv(), nil
+	}
+	env, err := vtenv.New(vtenv.Options{MySQLServerVersion: o.MySQLVersion})
+	if err != nil {
+		return nil, fmt.Errorf("invalid MySQL version %q: %w", o.MySQLVersion, err)
+	}
+	return env, nil
+}
+
+// ForeignKeys returns the foreign key mode, or unspecified when it is not set
+func (o PlannerOptions) ForeignKeys() (vschemapb.Keyspace_ForeignKeyMode, error) {
+	if o.ForeignKeyMode == "" {
+		return vschemapb.Keyspace_unspecified, nil
+	}
+	mode, ok := vschemapb.Keyspace_ForeignKeyMode_value[strings.ToLower(o.ForeignKeyMode)]
+	if !ok || mode == int32(vschemapb.Keyspace_unspecified) {
+		return vschemapb.Keyspace_unspecified, fmt.Errorf("invalid foreign key mode %q: must be one of unmanaged, managed or disallow", o.ForeignKeyMode)
+	}
+	return vschemapb.Keyspace_ForeignKeyMode(mode), nil
+}
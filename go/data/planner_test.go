@@ -0,0 +1,51 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package data
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	vschemapb "vitess.io/vitess/go/vt/proto/vschema"
+)
+
+func TestPlannerOptions(t *testing.T) {
+	env, err := PlannerOptions{}.Environment()
+	require.NoError(t, err)
+	assert.NotEmpty(t, env.MySQLVersion())
+
+	env, err = PlannerOptions{MySQLVersion: "8.4"}.Environment()
+	require.NoError(t, err)
+	assert.Equal(t, "8.4", env.MySQLVersion())
+
+	_, err = PlannerOptions{MySQLVersion: "latest"}.Environment()
+	require.Error(t, err)
+
+	mode, err := PlannerOptions{}.ForeignKeys()
+	require.NoError(t, err)
+	assert.Equal(t, vschemapb.Keyspace_unspecified, mode)
+
+	mode, err = PlannerOptions{ForeignKeyMode: "Disallow"}.ForeignKeys()
+	require.NoError(t, err)
+	assert.Equal(t, vschemapb.Keyspace_disallow, mode)
+
+	for _, invalid := range []string{"unspecified", "strict"} {
+		_, err = PlannerOptions{ForeignKeyMode: invalid}.ForeignKeys()
+		require.Error(t, err, invalid)
+	}
+}
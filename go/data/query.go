@@ -22,6 +22,7 @@ import (
 	"strings"
 
 	log "github.com/sirupsen/logrus"
+	querypb "vitess.io/vitess/go/vt/proto/query"
 )
 
 type (
@@ -49,6 +50,12 @@ type (
 		QueryTime, LockTime    float64
 		RowsSent, RowsExamined int
 		Timestamp              int64
+		// TimeMicros is the `# Time:` of the entry in microseconds since the epoch, when the query was logged
+		TimeMicros int64
+
+		// BindVars is only set by the vtgate log loader when it is asked to keep the bind variables
+		// separate from the query. Bind variables whose values are not logged are left out.
+		BindVars map[string]*querypb.BindVariable
 	}
 
 	errLoader struct {
@@ -59,6 +66,15 @@ type (
 // ForeachSQLQuery reads a query log file and calls the provided function for each normal SQL query in the log.
 // If the query log contains directives, they will be read and queries will be skipped as necessary.
 func ForeachSQLQuery(loader IteratorLoader, f func(Query) error) error {
+	return foreachQuery(loader, false, f)
+}
+
+// ForeachSQLQueryWithEvents works like ForeachSQLQuery, but also calls the provided function for connection events
+func ForeachSQLQueryWithEvents(loader IteratorLoader, f func(Query) error) error {
+	return foreachQuery(loader, true, f)
+}
+
+func foreachQuery(loader IteratorLoader, withEvents bool, f func(Query) error) error {
 	skip := false
 	usageCount := 1
 	for {
@@ -80,6 +96,13 @@ func ForeachSQLQuery(loader IteratorLoader, f func(Query) error) error {
 			return fmt.Errorf("unknown command type: %s", query.Type)
 		case Comment, CommentWithCommand, EmptyLine, WaitForAuthoritative, SkipIfBelowVersion:
 			// no-op for keys
+		case ConnectionEvent:
+			if !withEvents {
+				continue
+			}
+			if err := f(query); err != nil {
+				return err
+			}
 		case SQLQuery:
 			if skip {
 				skip = false
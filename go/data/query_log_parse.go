@@ -24,11 +24,16 @@ import (
 	"os"
 	"regexp"
 	"strconv"
+	"strings"
 	"sync"
 )
 
 type (
-	MySQLLogLoader struct{}
+	MySQLLogLoader struct {
+		// ConnectionEvents makes the loader return Connect, Quit and Change user events
+		// as queries of type ConnectionEvent, so session state can be tracked
+		ConnectionEvents bool
+	}
 
 	logReaderState struct {
 		fd         *os.File
@@ -45,6 +50,10 @@ type (
 		prevQuery        string
 		queryStart       int
 		prevConnectionID int
+
+		connectionEvents bool
+		// pendingEvent is a connection event that was read while a query was still being returned
+		pendingEvent *Query
 	}
 )
 
@@ -65,6 +74,12 @@ func (s *mysqlLogReaderState) Next() (Query, bool) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
+	if s.pendingEvent != nil {
+		event := *s.pendingEvent
+		s.pendingEvent = nil
+		return event, true
+	}
+
 	if s.closed {
 		return Query{}, false
 	}
@@ -91,6 +106,14 @@ func (s *mysqlLogReaderState) Next() (Query, bool) {
 			continue
 		}
 
+		if event, ok := s.connectionEvent(matches); ok {
+			if s.prevQuery != "" {
+				s.pendingEvent = &event
+				return s.finalizeQuery(), true
+			}
+			return event, true
+		}
+
 		// If we have a previous query, return it before processing the new line
 		if s.prevQuery != "" {
 			return s.processQuery(matches), true
@@ -155,6 +178,35 @@ func (s *mysqlLogReaderState) finalizeQuery() Query {
 	return query
 }
 
+// connectionEvent returns the event if the line is a Connect, Quit or Change user command
+// and the loader was asked to return connection events
+func (s *mysqlLogReaderState) connectionEvent(matches []string) (Query, bool) {
+	if !s.connectionEvents {
+		return Query{}, false
+	}
+	event := matches[3]
+	argument := matches[4]
+	switch {
+	case event == ConnectEvent || event == QuitEvent:
+	case event == "Change" && strings.HasPrefix(argument, "user"):
+		event = ChangeUserEvent
+		argument = strings.TrimSpace(strings.TrimPrefix(argument, "user"))
+	default:
+		return Query{}, false
+	}
+	connID, err := strconv.Atoi(matches[2])
+	if err != nil {
+		return Query{}, false
+	}
+	return Query{
+		FirstWord:    event,
+		Query:        argument,
+		Line:         s.lineNumber,
+		Type:         ConnectionEvent,
+		ConnectionID: connID,
+	}, true
+}
+
 func (s *mysqlLogReaderState) processQuery(matches []string) Query {
 	query := Query{
 		Query:        s.prevQuery,
@@ -194,7 +246,7 @@ func (s *logReaderState) Close() error {
 	return s.err
 }
 
-func (MySQLLogLoader) Load(fileName string) IteratorLoader {
+func (l MySQLLogLoader) Load(fileName string) IteratorLoader {
 	reg := regexp.MustCompile(`^(\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}\.\d{6}Z)\s+(\d+)\s+(\w+)\s+(.*)`)
 
 	fd, err := os.OpenFile(fileName, os.O_RDONLY, 0)
@@ -208,5 +260,6 @@ func (MySQLLogLoader) Load(fileName string) IteratorLoader {
 			reg:    reg,
 			fd:     fd,
 		},
+		connectionEvents: l.ConnectionEvents,
 	}
 }
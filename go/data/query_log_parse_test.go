@@ -17,6 +17,8 @@ limitations under the License.
 package data
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -81,3 +83,56 @@ LIMIT 1`,
 
 	require.Equal(t, expected, gotQueries)
 }
+
+func TestConnectionEvents(t *testing.T) {
+	loader := MySQLLogLoader{ConnectionEvents: true}.Load("../testdata/query-logs/mysql.small-query.log")
+	gotQueries, err := makeSlice(loader)
+	require.NoError(t, err)
+
+	var events []Query
+	for _, query := range gotQueries {
+		if query.Type == ConnectionEvent {
+			events = append(events, query)
+		}
+	}
+	require.Len(t, events, 1)
+	assert.Equal(t, ConnectEvent, events[0].FirstWord)
+	assert.Equal(t, 33, events[0].ConnectionID)
+	assert.Equal(t, "vt_dba@localhost on vt_uks using Socket", events[0].Query)
+
+	// the query read before the event is still returned, and in order
+	withoutEvents, err := makeSlice(MySQLLogLoader{}.Load("../testdata/query-logs/mysql.small-query.log"))
+	require.NoError(t, err)
+	assert.Len(t, gotQueries, len(withoutEvents)+1)
+}
+
+func TestConnectionEventsChangeUserAndQuit(t *testing.T) {
+	log := filepath.Join(t.TempDir(), "general.log")
+	err := os.WriteFile(log, []byte(`Time                 Id Command    Argument
+2024-11-06T09:57:35.100000Z	    7 Query	update t set a = 1
+where id = 1
+2024-11-06T09:57:35.300000Z	    7 Change user	admin@localhost on shop
+2024-11-06T09:57:35.400000Z	    7 Query	update t set a = 2 where id = 2
+2024-11-06T09:57:35.700000Z	    7 Quit	
+`), 0o600)
+	require.NoError(t, err)
+
+	gotQueries, err := makeSlice(MySQLLogLoader{ConnectionEvents: true}.Load(log))
+	require.NoError(t, err)
+
+	var got []string
+	for _, query := range gotQueries {
+		assert.Equal(t, 7, query.ConnectionID)
+		if query.Type == ConnectionEvent {
+			got = append(got, query.FirstWord+": "+query.Query)
+			continue
+		}
+		got = append(got, query.Query)
+	}
+	assert.Equal(t, []string{
+		"update t set a = 1\nwhere id = 1",
+		"Change user: admin@localhost on shop",
+		"update t set a = 2 where id = 2",
+		"Quit: ",
+	}, got)
+}
@@ -26,6 +26,7 @@ import (
 	"os"
 	"strconv"
 	"strings"
+	"time"
 )
 
 type SlowQueryLogLoader struct{}
@@ -129,6 +130,13 @@ func (s *slowQueryLogReaderState) processLine(line string, state *lineProcessorS
 }
 
 func (s *slowQueryLogReaderState) processCommentLine(line string, state *lineProcessorState) (bool, error) {
+	if t, ok := strings.CutPrefix(line, "# Time:"); ok {
+		// older servers log the time in seconds in another format, which only SET timestamp is used for
+		if ts, err := time.Parse(time.RFC3339Nano, strings.TrimSpace(t)); err == nil {
+			state.currentQuery.TimeMicros = ts.UnixMicro()
+		}
+		return false, nil
+	}
 	if strings.HasPrefix(line, "# Query_time:") || strings.HasPrefix(line, "# User@Host:") {
 		if err := parseQueryMetrics(line, &state.currentQuery); err != nil {
 			return false, err
@@ -290,6 +298,7 @@ func parseQuery(rs Query) (*Query, error) {
 		RowsSent:     rs.RowsSent,
 		RowsExamined: rs.RowsExamined,
 		Timestamp:    rs.Timestamp,
+		TimeMicros:   rs.TimeMicros,
 		ConnectionID: rs.ConnectionID,
 	}
 
@@ -36,6 +36,16 @@ const (
 	Reference
 	UsageCount
 	AllowDifferentFieldSizes
+	// ConnectionEvent is a connection being opened, closed or changing user.
+	// The kind of event is stored in the FirstWord field of the query.
+	ConnectionEvent
+)
+
+// The connection events recognized in the MySQL general query log
+const (
+	ConnectEvent    = "Connect"
+	QuitEvent       = "Quit"
+	ChangeUserEvent = "Change user"
 )
 
 var commandMap = map[string]CmdType{ //nolint:gochecknoglobals // this is instead of a const
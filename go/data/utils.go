@@ -32,14 +32,18 @@ const (
 	DBInfoFile
 	TransactionFile
 	PlanalyzeFile
+	PlanalyzeCompareFile
+	DBInfoDiffFile
 )
 
 var fileTypeMap = map[string]FileType{ //nolint:gochecknoglobals // this is instead of a const
-	"trace":        TraceFile,
-	"keys":         KeysFile,
-	"dbinfo":       DBInfoFile,
-	"transactions": TransactionFile,
-	"planalyze":    PlanalyzeFile,
+	"trace":            TraceFile,
+	"keys":             KeysFile,
+	"dbinfo":           DBInfoFile,
+	"transactions":     TransactionFile,
+	"planalyze":        PlanalyzeFile,
+	"planalyzeCompare": PlanalyzeCompareFile,
+	"dbinfoDiff":       DBInfoDiffFile,
 }
 
 // GetFileType reads the first key-value pair from a JSON file and returns the type of the file
@@ -38,11 +38,16 @@ type (
 	}
 	VtGateLogLoader struct {
 		NeedsBindVars bool
+
+		// KeepBindVars leaves the query normalized and returns the logged bind variables in Query.BindVars.
+		// Unlike NeedsBindVars, queries with redacted or tuple bind variables are not an error.
+		KeepBindVars bool
 	}
 
 	vtgateLogReaderState struct {
 		logReaderState
 		NeedsBindVars bool
+		KeepBindVars  bool
 		uuidReg       *regexp.Regexp
 	}
 )
@@ -63,6 +68,7 @@ func (vll VtGateLogLoader) Load(fileName string) IteratorLoader {
 			fd:     fd,
 		},
 		NeedsBindVars: vll.NeedsBindVars,
+		KeepBindVars:  vll.KeepBindVars,
 		uuidReg:       uuidReg,
 	}
 }
@@ -108,6 +114,21 @@ func (s *vtgateLogReaderState) Next() (Query, bool) {
 			return Query{}, false
 		}
 
+		if s.KeepBindVars {
+			bvs, err := getBindVariables(match[2], s.lineNumber, true)
+			if err != nil {
+				s.fail(err)
+				return Query{}, false
+			}
+			return Query{
+				Query:        query,
+				Line:         s.lineNumber,
+				Type:         SQLQuery,
+				ConnectionID: connectionID,
+				BindVars:     bvs,
+			}, true
+		}
+
 		if !s.NeedsBindVars {
 			return Query{
 				Query:        query,
@@ -122,7 +143,7 @@ func (s *vtgateLogReaderState) Next() (Query, bool) {
 		// can understand (map[string]*querypb.BindVariable), parse the query string,
 		// and add the bind variables to it.
 		bindVarsRaw := match[2]
-		bvs, err := getBindVariables(bindVarsRaw, s.lineNumber)
+		bvs, err := getBindVariables(bindVarsRaw, s.lineNumber, false)
 		if err != nil {
 			s.fail(err)
 			return Query{}, false
@@ -178,8 +199,13 @@ func addBindVarsToQuery(query string, bvs map[string]*querypb.BindVariable) (str
 	return pq.GenerateQuery(bvs, nil)
 }
 
-func getBindVariables(bindVarsRaw string, lineNumber int) (map[string]*querypb.BindVariable, error) {
+// getBindVariables parses the bind variables logged by vtgate. When skipUnknown is set, values that were
+// not logged (redacted queries and tuples) are left out instead of failing.
+func getBindVariables(bindVarsRaw string, lineNumber int, skipUnknown bool) (map[string]*querypb.BindVariable, error) {
 	if strings.Contains(bindVarsRaw, "[REDACTED]") {
+		if skipUnknown {
+			return nil, nil
+		}
 		return nil, fmt.Errorf("line %d: query has redacted bind variables, cannot parse them", lineNumber)
 	}
 
@@ -210,6 +236,9 @@ func getBindVariables(bindVarsRaw string, lineNumber int) (map[string]*querypb.B
 		case bvType == sqltypes.Tuple:
 			// the query log of vtgate does not list all the values for a tuple
 			// instead it lists the following: "v2": {"type": "TUPLE", "value": "2 items"}
+			if skipUnknown {
+				continue
+			}
 			return nil, fmt.Errorf("line %d: cannot parse tuple bind variables", lineNumber)
 		}
 		if val == nil {
@@ -66,6 +66,22 @@ func TestParseVtGateQueryLogNoBindVars(t *testing.T) {
 	require.Equal(t, string(expect), strings.Join(got, "\n"))
 }
 
+func TestParseVtGateQueryLogKeepBindVars(t *testing.T) {
+	loader := VtGateLogLoader{KeepBindVars: true}.Load("../testdata/query-logs/vtgate.query.log")
+	gotQueries, err := makeSlice(loader)
+	require.NoError(t, err)
+	require.Len(t, gotQueries, 25)
+
+	// the query text is left untouched, and the values are available on the side
+	require.Contains(t, gotQueries[4].Query, ":vtg1")
+	require.Equal(t, "110001", string(gotQueries[4].BindVars["vtg1"].Value))
+
+	loader = VtGateLogLoader{KeepBindVars: true}.Load("../testdata/query-logs/vtgate.query.log.redacted")
+	gotQueries, err = makeSlice(loader)
+	require.NoError(t, err)
+	require.Nil(t, gotQueries[0].BindVars)
+}
+
 func format(query Query) string {
 	return fmt.Sprintf("%d:%s", query.ConnectionID, query.Query)
 }
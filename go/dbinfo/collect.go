@@ -0,0 +1,104 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dbinfo
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+)
+
+// collectors run the parts of dbinfo concurrently. A part that fails leaves a warning
+// instead of failing dbinfo, so that everything else is still reported.
+type collectors struct {
+	wg       sync.WaitGroup
+	mu       sync.Mutex
+	warnings []string
+}
+
+func (c *collectors) run(what string, fn func() error) {
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+		if err := fn(); err != nil {
+			c.warn(fmt.Sprintf("%s: %v", what, err))
+		}
+	}()
+}
+
+func (c *collectors) warn(warning string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.warnings = append(c.warnings, warning)
+}
+
+// wait waits for the collectors to finish, and returns all their warnings so far, sorted
+func (c *collectors) wait() []string {
+	c.wg.Wait()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	warnings := slices.Clone(c.warnings)
+	slices.Sort(warnings)
+	return warnings
+}
+
+// schemaInfo is what could be loaded of a schema
+type schemaInfo struct {
+	tables  []*TableInfo
+	objects schemaObjects
+}
+
+// getSchema loads the tables and objects of the schema of the helper, the parts that fail are left out with a warning
+func getSchema(ctx context.Context, dbh *DBHelper, sample SampleConfig) (*schemaInfo, []string) {
+	prefix := "database " + dbh.vtParams.DbName + ": loading "
+	var c collectors
+	var sizes tableSizes
+	var columns tableColumns
+	var pks primaryKeys
+	var idxs map[string]*tableIndex
+	var fks map[string][]*ForeignKey
+	c.run(prefix+"table sizes", func() (err error) { sizes, err = dbh.getTableSizes(ctx); return err })
+	c.run(prefix+"columns", func() (err error) { columns, err = dbh.getColumnInfo(ctx); return err })
+	c.run(prefix+"primary keys", func() (err error) { pks, err = dbh.getPrimaryKeys(ctx); return err })
+	c.run(prefix+"indexes", func() (err error) { idxs, err = dbh.getIndexes(ctx); return err })
+	c.run(prefix+"foreign keys", func() (err error) { fks, err = dbh.getForeignKeys(ctx); return err })
+
+	si := &schemaInfo{}
+	objects := &si.objects
+	c.run(prefix+"routines", func() (err error) { objects.routines, err = dbh.getRoutines(ctx); return err })
+	c.run(prefix+"triggers", func() (err error) { objects.triggers, err = dbh.getTriggers(ctx); return err })
+	c.run(prefix+"events", func() (err error) { objects.events, err = dbh.getEvents(ctx); return err })
+	c.run(prefix+"views", func() (err error) { objects.views, err = dbh.getViews(ctx); return err })
+	c.wait()
+
+	tableMap := make(map[string]*TableInfo)
+	addTableSizes(tableMap, sizes)
+	addColumns(tableMap, columns)
+	addPrimaryKeys(tableMap, pks)
+	addIndexes(tableMap, idxs)
+	addForeignKeys(tableMap, fks)
+
+	// the columns to sample are only known once the columns are loaded
+	getSamples(ctx, dbh, tableMap, sample, &c)
+	warnings := c.wait()
+
+	for _, ti := range tableMap {
+		si.tables = append(si.tables, ti)
+	}
+	return si, warnings
+}
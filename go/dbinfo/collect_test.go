@@ -0,0 +1,122 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dbinfo
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"vitess.io/vitess/go/mysql/fakesqldb"
+	"vitess.io/vitess/go/sqltypes"
+)
+
+func TestGetPartial(t *testing.T) {
+	db := fakesqldb.New(t)
+	defer db.Close()
+	db.SetNeverFail(true)
+
+	// the schema name is bound to the queries as an escaped string literal
+	db.AddQueryPattern(`.*from information_schema\.tables where table_schema = 'o\\'brien' and.*`, sqltypes.MakeTestResult(
+		sqltypes.MakeTestFields("table_name|table_rows|engine|data_length|index_length|avg_row_length|auto_increment",
+			"varchar|int64|varchar|int64|int64|int64|uint64"),
+		"orders|10|InnoDB|16384|0|1638|11",
+	))
+	db.RejectQueryPattern(`.*from information_schema\.key_column_usage where constraint_name = 'PRIMARY'.*`, "access denied")
+	db.AddQueryPatternWithCallback(`.*from information_schema\.statistics.*`, &sqltypes.Result{}, func(string) {
+		time.Sleep(time.Second)
+	})
+
+	si, err := Get(context.Background(), Config{
+		VTParams:     *db.ConnParams(),
+		Databases:    []string{"o'brien"},
+		Connections:  2,
+		QueryTimeout: 100 * time.Millisecond,
+	})
+	require.NoError(t, err)
+
+	require.Len(t, si.Tables, 1)
+	assert.Equal(t, "orders", si.Tables[0].Name)
+	assert.Equal(t, 10, si.Tables[0].Rows)
+	assert.Equal(t, uint64(11), si.Tables[0].AutoIncrement)
+
+	require.Len(t, si.Warnings, 2)
+	assert.Contains(t, si.Warnings[0], "database o'brien: loading indexes: query interrupted after 100ms")
+	assert.Contains(t, si.Warnings[1], "database o'brien: loading primary keys: ")
+	assert.Contains(t, si.Warnings[1], "access denied")
+}
+
+func TestGetManySchemas(t *testing.T) {
+	db := fakesqldb.New(t)
+	defer db.Close()
+	db.SetNeverFail(true)
+	db.AddQueryPattern(`.*from information_schema\.tables where table_schema = '(\w+)' and.*`, sqltypes.MakeTestResult(
+		sqltypes.MakeTestFields("table_name|table_rows|engine|data_length|index_length|avg_row_length|auto_increment",
+			"varchar|int64|varchar|int64|int64|int64|uint64"),
+		"orders|10|InnoDB|16384|0|1638|11",
+	))
+
+	// more schemas than are loaded at the same time
+	databases := []string{"a", "b", "c", "d", "e"}
+	si, err := Get(context.Background(), Config{
+		VTParams:    *db.ConnParams(),
+		Databases:   databases,
+		Connections: 1,
+	})
+	require.NoError(t, err)
+	assert.Empty(t, si.Warnings)
+	require.Len(t, si.Tables, len(databases))
+	for i, ti := range si.Tables {
+		assert.Equal(t, databases[i]+".orders", ti.QualifiedName())
+	}
+}
+
+func TestFetchTimeoutExcludesWaiting(t *testing.T) {
+	db := fakesqldb.New(t)
+	defer db.Close()
+	sleep := func(d time.Duration) func(string) {
+		return func(string) { time.Sleep(d) }
+	}
+	db.AddQueryPatternWithCallback(`select 'slow'`, &sqltypes.Result{}, sleep(250*time.Millisecond))
+	db.AddQueryPatternWithCallback(`select 'waiting'`, &sqltypes.Result{}, sleep(150*time.Millisecond))
+
+	pool := newConnPool(db.ConnParams(), 1, 300*time.Millisecond)
+	defer pool.close()
+
+	slow := make(chan error)
+	go func() {
+		_, err := pool.fetch(context.Background(), "select 'slow'")
+		slow <- err
+	}()
+	time.Sleep(50 * time.Millisecond)
+
+	// this query waits for the only connection for 200ms, and then runs for 150ms, within the timeout
+	_, err := pool.fetch(context.Background(), "select 'waiting'")
+	require.NoError(t, err)
+	require.NoError(t, <-slow)
+}
+
+func TestGetUnreachable(t *testing.T) {
+	db := fakesqldb.New(t)
+	params := *db.ConnParams()
+	db.Close()
+
+	_, err := Get(context.Background(), Config{VTParams: params, Databases: []string{"shop"}})
+	require.Error(t, err)
+}
@@ -0,0 +1,88 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dbinfo
+
+import (
+	"fmt"
+	"strings"
+
+	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/vtgate/vindexes"
+)
+
+// VindexColumn is the column as the planner sees it in an authoritative column list
+func (c *TableColumn) VindexColumn() vindexes.Column {
+	return vindexes.Column{
+		Name:          sqlparser.NewIdentifierCI(c.Name),
+		Type:          sqlparser.SQLTypeToQueryType(c.Type, strings.Contains(c.ColumnType, "unsigned")),
+		CollationName: c.Collation,
+		Nullable:      c.IsNullable,
+		Invisible:     strings.Contains(c.Extra, "invisible"),
+	}
+}
+
+const (
+	// hotValueShare is the share of the sampled rows with the same value from which a sharding key makes a hot shard
+	hotValueShare = 0.1
+	// minDistinctValues is the number of distinct values under which a sharding key cannot spread the rows evenly
+	minDistinctValues = 100
+	// minSampledRows is the number of sampled rows under which the skew says nothing about the shards,
+	// a value has to show up ten times to be called hot
+	minSampledRows = max(minDistinctValues, 10/hotValueShare)
+	// minNullRatio is the share of NULL values worth reporting, they all end up on the same shard
+	minNullRatio = 0.01
+)
+
+// ShardingKeyIssues lists why the column makes a poor sharding key, it is empty for a good candidate
+func (c *TableColumn) ShardingKeyIssues() []string {
+	var issues []string
+	if c.IsNullable {
+		// rows with a NULL sharding key all go to the same shard
+		issues = append(issues, "nullable")
+	}
+	switch c.Type {
+	case "float", "double":
+		issues = append(issues, c.Type+" values do not compare exactly")
+	case "tinytext", "text", "mediumtext", "longtext", "tinyblob", "blob", "mediumblob", "longblob", "json",
+		"geometry", "point", "linestring", "polygon", "multipoint", "multilinestring", "multipolygon", "geometrycollection":
+		issues = append(issues, c.Type+" values are too large to hash")
+	case "enum", "set", "bit", "year":
+		issues = append(issues, c.Type+" has too few distinct values")
+	case "date", "datetime", "timestamp":
+		// a hash of the value cannot route a range, so the usual queries on a point in time go to every shard
+		issues = append(issues, c.Type+" values are mostly filtered by range, which scatters")
+	}
+	return append(issues, c.Sample.hotShardIssues()...)
+}
+
+// hotShardIssues reports the skew found by sampling the values of the column
+func (s *ColumnSample) hotShardIssues() []string {
+	if s == nil || s.Rows < minSampledRows {
+		return nil
+	}
+	var issues []string
+	if s.NullRatio >= minNullRatio {
+		issues = append(issues, fmt.Sprintf("%.0f%% of the sampled values are NULL", s.NullRatio*100))
+	}
+	if share := s.TopShare(); share >= hotValueShare {
+		issues = append(issues, fmt.Sprintf("hot shard: %q is %.0f%% of the sampled rows", s.TopValues[0].Value, share*100))
+	}
+	if s.Distinct < minDistinctValues && s.Distinct < s.Rows {
+		issues = append(issues, fmt.Sprintf("hot shards: only %d distinct values in %d sampled rows", s.Distinct, s.Rows))
+	}
+	return issues
+}
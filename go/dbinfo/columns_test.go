@@ -0,0 +1,76 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dbinfo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"vitess.io/vitess/go/sqltypes"
+)
+
+func TestShardingKeyIssues(t *testing.T) {
+	assert.Empty(t, (&TableColumn{Name: "id", Type: "bigint"}).ShardingKeyIssues())
+	assert.Empty(t, (&TableColumn{Name: "email", Type: "varchar"}).ShardingKeyIssues())
+	assert.Equal(t, []string{"nullable"},
+		(&TableColumn{Name: "amount", Type: "decimal", IsNullable: true}).ShardingKeyIssues())
+	assert.Equal(t, []string{"double values do not compare exactly"},
+		(&TableColumn{Name: "ratio", Type: "double"}).ShardingKeyIssues())
+	assert.Equal(t, []string{"date values are mostly filtered by range, which scatters"},
+		(&TableColumn{Name: "day", Type: "date"}).ShardingKeyIssues())
+	assert.Equal(t, []string{"enum has too few distinct values"},
+		(&TableColumn{Name: "status", Type: "enum"}).ShardingKeyIssues())
+}
+
+func TestVindexColumn(t *testing.T) {
+	col := (&TableColumn{Name: "id", Type: "int", ColumnType: "int unsigned", IsNullable: true, Extra: "invisible"}).VindexColumn()
+	assert.Equal(t, "id", col.Name.String())
+	assert.Equal(t, sqltypes.Uint32, col.Type)
+	assert.True(t, col.Nullable)
+	assert.True(t, col.Invisible)
+}
+
+func TestShardingKeyIssuesFromSample(t *testing.T) {
+	col := &TableColumn{Name: "tenant_id", Type: "bigint", IsNullable: true, Sample: &ColumnSample{
+		Rows:      1000,
+		Distinct:  12,
+		NullRatio: 0.05,
+		TopValues: []ValueCount{{Value: "42", Count: 400}, {Value: "7", Count: 100}},
+	}}
+	assert.Equal(t, []string{
+		"nullable",
+		"5% of the sampled values are NULL",
+		`hot shard: "42" is 40% of the sampled rows`,
+		"hot shards: only 12 distinct values in 1000 sampled rows",
+	}, col.ShardingKeyIssues())
+
+	// a unique column is fine, even when the table is smaller than the number of distinct values needed
+	col = &TableColumn{Name: "id", Type: "bigint", Sample: &ColumnSample{
+		Rows:      50,
+		Distinct:  50,
+		TopValues: []ValueCount{{Value: "1", Count: 1}},
+	}}
+	assert.Empty(t, col.ShardingKeyIssues())
+
+	// in a tiny table every value of a unique column is a large share of the rows
+	col = &TableColumn{Name: "code", Type: "char", Sample: &ColumnSample{
+		Rows:      8,
+		Distinct:  8,
+		TopValues: []ValueCount{{Value: "EUR", Count: 1}},
+	}}
+	assert.Empty(t, col.ShardingKeyIssues())
+}
@@ -17,30 +17,77 @@ limitations under the License.
 package dbinfo
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
 	"os"
+	"os/signal"
 	"sort"
+	"strings"
+	"time"
 
 	"vitess.io/vitess/go/mysql"
 
 	"github.com/vitessio/vt/go/data"
 )
 
+var ErrNoDatabase = errors.New("no database to load, give one or more databases or load all of them")
+
+// concurrentSchemas is the number of schemas loaded at the same time
+const concurrentSchemas = 2
+
 type Config struct {
 	VTParams mysql.ConnParams
+
+	// Databases are the schemas to load, the database of VTParams is loaded when it is empty
+	Databases []string
+
+	// AllDatabases loads all schemas except the system ones, ignoring Databases
+	AllDatabases bool
+
+	// Sample is only used when it has columns
+	Sample SampleConfig
+
+	// GlobalVariables are names or regular expressions of global variables to collect
+	// on top of DefaultGlobalVariables
+	GlobalVariables []string
+
+	// SQLFile is a schema dump to read the tables from instead of the database
+	SQLFile string
+
+	// RowCountsFile is a CSV file of table names and row counts, used with SQLFile
+	RowCountsFile string
+
+	// Connections is the number of connections the queries run on concurrently, DefaultConnections when not set
+	Connections int
+
+	// QueryTimeout is how long a query can run before it is killed, DefaultQueryTimeout when not set
+	QueryTimeout time.Duration
 }
 
 func Run(cfg Config) error {
-	return run(os.Stdout, cfg)
+	// interrupting dbinfo kills the queries it is running
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	return run(ctx, os.Stdout, cfg)
 }
 
-func run(out io.Writer, cfg Config) error {
-	si, err := Get(cfg)
+func run(ctx context.Context, out io.Writer, cfg Config) error {
+	var si *Info
+	var err error
+	if cfg.SQLFile != "" {
+		si, err = FromSQL(cfg.SQLFile, cfg.RowCountsFile)
+	} else {
+		si, err = Get(ctx, cfg)
+	}
 	if err != nil {
 		return err
 	}
+	for _, warning := range si.Warnings {
+		fmt.Fprintln(os.Stderr, "warning:", warning)
+	}
 	b, err := json.MarshalIndent(si, "", "  ")
 	if err != nil {
 		return err
@@ -50,11 +97,35 @@ func run(out io.Writer, cfg Config) error {
 }
 
 type TableColumn struct {
-	Name       string `json:"name"`
-	Type       string `json:"type"`
+	Name string `json:"name"`
+	Type string `json:"type"`
+	// ColumnType is the full type of the column, like varchar(255) or int unsigned
+	ColumnType string `json:"columnType,omitempty"`
 	KeyType    string `json:"keyType,omitempty"`
 	IsNullable bool   `json:"isNullable,omitempty"`
 	Extra      string `json:"extra,omitempty"`
+	Charset    string `json:"charset,omitempty"`
+	Collation  string `json:"collation,omitempty"`
+	// Default is nil when the column has no default value
+	Default *string `json:"default,omitempty"`
+	// GenerationExpression is only set for generated columns
+	GenerationExpression string `json:"generationExpression,omitempty"`
+	// Sample is only set for the columns sampled with vt dbinfo --sample
+	Sample *ColumnSample `json:"sample,omitempty"`
+}
+
+// TableSize is how much storage a table takes, as estimated by information_schema.tables
+type TableSize struct {
+	Engine        string `json:"engine,omitempty"`
+	DataLength    int64  `json:"dataLength,omitempty"`
+	IndexLength   int64  `json:"indexLength,omitempty"`
+	AvgRowLength  int64  `json:"avgRowLength,omitempty"`
+	AutoIncrement uint64 `json:"autoIncrement,omitempty"`
+}
+
+// Total is the size of the data and the indexes of the table
+func (ts TableSize) Total() int64 {
+	return ts.DataLength + ts.IndexLength
 }
 
 type PrimaryKey struct {
@@ -65,37 +136,53 @@ type Index struct {
 	Name      string
 	Columns   []string `json:"columns"`
 	NonUnique bool     `json:"nonUnique,omitempty"`
+	// Cardinality has the estimated number of distinct values of the index prefix ending at each column
+	Cardinality []int64 `json:"cardinality,omitempty"`
 }
 
 type ForeignKey struct {
-	ColumnName           string `json:"columnName"`
-	ConstraintName       string `json:"constraintName"`
+	ColumnName     string `json:"columnName"`
+	ConstraintName string `json:"constraintName"`
+	// ReferencedSchemaName is only set when the referenced table is in another schema
+	ReferencedSchemaName string `json:"referencedSchemaName,omitempty"`
 	ReferencedTableName  string `json:"referencedTableName"`
 	ReferencedColumnName string `json:"referencedColumnName"`
 }
 
 type TableInfo struct {
-	Name        string         `json:"name"`
-	Rows        int            `json:"rows"`
+	Name string `json:"name"`
+	// Schema is only set when several schemas were loaded
+	Schema string `json:"schema,omitempty"`
+	Rows   int    `json:"rows"`
+	TableSize
 	Columns     []*TableColumn `json:"columns"`
 	PrimaryKey  *PrimaryKey    `json:"primaryKey,omitempty"`
 	Indexes     []*Index       `json:"indexes,omitempty"`
 	ForeignKeys []*ForeignKey  `json:"foreignKeys,omitempty"`
 }
 
+// QualifiedName is the name of the table, qualified by its schema when it has one
+func (ti *TableInfo) QualifiedName() string {
+	if ti.Schema == "" {
+		return ti.Name
+	}
+	return ti.Schema + "." + ti.Name
+}
+
 type Info struct {
 	FileType        string            `json:"fileType"`
 	Tables          []*TableInfo      `json:"tables"`
+	Views           []*View           `json:"views,omitempty"`
+	Triggers        []*Trigger        `json:"triggers,omitempty"`
+	Routines        []*Routine        `json:"routines,omitempty"`
+	Events          []*Event          `json:"events,omitempty"`
 	GlobalVariables map[string]string `json:"globalVariables"`
+	// Warnings are the parts of the database that could not be loaded
+	Warnings []string `json:"warnings,omitempty"`
 }
 
-func getTableSizes(dbh *DBHelper, tableMap map[string]*TableInfo) error {
-	ts, err := dbh.getTableSizes()
-	if err != nil {
-		return err
-	}
-
-	for tableName, tableRows := range ts {
+func addTableSizes(tableMap map[string]*TableInfo, ts tableSizes) {
+	for tableName, size := range ts {
 		ti, ok := tableMap[tableName]
 		if !ok {
 			ti = &TableInfo{
@@ -103,113 +190,162 @@ func getTableSizes(dbh *DBHelper, tableMap map[string]*TableInfo) error {
 			}
 			tableMap[tableName] = ti
 		}
-		ti.Rows = tableRows
+		ti.Rows = size.rows
+		ti.TableSize = size.TableSize
 	}
-	return nil
 }
 
-func getPrimaryKeys(dbh *DBHelper, tableMap map[string]*TableInfo) error {
-	pks, err := dbh.getPrimaryKeys()
-	if err != nil {
-		return err
-	}
-
-	for tableName, pk := range pks {
+func addColumns(tableMap map[string]*TableInfo, tc tableColumns) {
+	for tableName, columns := range tc {
 		ti, ok := tableMap[tableName]
 		if !ok {
-			ti = &TableInfo{}
-			tableMap[tableName] = ti
-		}
-		ti.PrimaryKey = &PrimaryKey{
-			Columns: pk.columns,
+			// views have columns but are not in the table sizes
+			continue
 		}
+		ti.Columns = columns
 	}
-	return nil
 }
 
-func getIndexes(dbh *DBHelper, tableMap map[string]*TableInfo) error {
-	idxs, err := dbh.getIndexes()
-	if err != nil {
-		return err
-	}
-
-	for tableName, tidx := range idxs {
-		ti, ok := tableMap[tableName]
+// getSamples samples the columns of the config that exist in the database,
+// the columns of a keys file can belong to tables of other schemas
+func getSamples(ctx context.Context, dbh *DBHelper, tableMap map[string]*TableInfo, cfg SampleConfig, c *collectors) {
+	for _, col := range cfg.Columns {
+		ti, ok := tableMap[col.Table]
 		if !ok {
-			ti = &TableInfo{}
-			tableMap[tableName] = ti
+			continue
 		}
-		for _, idx := range tidx.indexes {
-			ti.Indexes = append(ti.Indexes, idx)
+		for _, tc := range ti.Columns {
+			if !strings.EqualFold(tc.Name, col.Column) {
+				continue
+			}
+			what := fmt.Sprintf("database %s: sampling %s.%s", dbh.vtParams.DbName, col.Table, col.Column)
+			c.run(what, func() (err error) {
+				tc.Sample, err = dbh.getColumnSample(ctx, col, cfg)
+				return err
+			})
 		}
 	}
-	return nil
 }
 
-func getForeignKeys(dbh *DBHelper, tableMap map[string]*TableInfo) error {
-	fks, err := dbh.getForeignKeys()
-	if err != nil {
-		return err
+// tableOf returns the table of the map, adding it when needed
+func tableOf(tableMap map[string]*TableInfo, tableName string) *TableInfo {
+	ti, ok := tableMap[tableName]
+	if !ok {
+		ti = &TableInfo{Name: tableName}
+		tableMap[tableName] = ti
 	}
+	return ti
+}
 
-	for fkName, fk := range fks {
-		ti, ok := tableMap[fkName]
-		if !ok {
-			ti = &TableInfo{}
-			tableMap[fkName] = ti
+func addPrimaryKeys(tableMap map[string]*TableInfo, pks primaryKeys) {
+	for tableName, pk := range pks {
+		tableOf(tableMap, tableName).PrimaryKey = &PrimaryKey{
+			Columns: pk.columns,
 		}
-		ti.ForeignKeys = fk
 	}
-	return nil
 }
 
-func Get(cfg Config) (*Info, error) {
-	vtParams := &mysql.ConnParams{
-		Host:   cfg.VTParams.Host,
-		Port:   cfg.VTParams.Port,
-		Uname:  cfg.VTParams.Uname,
-		Pass:   cfg.VTParams.Pass,
-		DbName: cfg.VTParams.DbName,
+func addIndexes(tableMap map[string]*TableInfo, idxs map[string]*tableIndex) {
+	for tableName, tidx := range idxs {
+		ti := tableOf(tableMap, tableName)
+		for _, idx := range tidx.indexes {
+			ti.Indexes = append(ti.Indexes, idx)
+		}
 	}
+}
 
-	var tableInfo []*TableInfo
-	tableMap := make(map[string]*TableInfo)
+func addForeignKeys(tableMap map[string]*TableInfo, fks map[string][]*ForeignKey) {
+	for tableName, fk := range fks {
+		tableOf(tableMap, tableName).ForeignKeys = fk
+	}
+}
 
-	dbh := NewDBHelper(vtParams)
+func Get(ctx context.Context, cfg Config) (*Info, error) {
+	// all the connection parameters are kept, like the socket and the TLS settings
+	params := cfg.VTParams
+	vtParams := &params
 
-	globalVariables, err := dbh.getGlobalVariables()
-	if err != nil {
-		return nil, err
+	schemas := cfg.Databases
+	if len(schemas) == 0 && vtParams.DbName != "" {
+		schemas = []string{vtParams.DbName}
 	}
-
-	if err := getTableSizes(dbh, tableMap); err != nil {
-		return nil, err
+	if len(schemas) == 0 && !cfg.AllDatabases {
+		return nil, ErrNoDatabase
 	}
 
-	if err := getPrimaryKeys(dbh, tableMap); err != nil {
-		return nil, err
-	}
+	dbh := &DBHelper{vtParams: vtParams, pool: newConnPool(vtParams, cfg.Connections, cfg.QueryTimeout)}
+	defer dbh.pool.close()
 
-	if err := getIndexes(dbh, tableMap); err != nil {
+	// the database has to be reachable, after that the parts that fail to load only leave warnings
+	if _, err := dbh.fetch(ctx, "select 1"); err != nil {
 		return nil, err
 	}
+	if cfg.AllDatabases {
+		var err error
+		schemas, err = dbh.getSchemas(ctx)
+		if err != nil {
+			return nil, err
+		}
+	}
 
-	if err := getForeignKeys(dbh, tableMap); err != nil {
-		return nil, err
+	dbInfo := &Info{
+		FileType:        "dbinfo",
+		GlobalVariables: map[string]string{},
+	}
+	var c collectors
+	c.run("loading global variables", func() error {
+		globalVariables, err := dbh.getGlobalVariables(ctx, append(DefaultGlobalVariables(), cfg.GlobalVariables...))
+		if err == nil {
+			dbInfo.GlobalVariables = globalVariables
+		}
+		return err
+	})
+	// the collectors of a schema already share the connections, loading a few schemas at a time keeps them
+	// busy without queuing the collectors of every schema
+	loading := make(chan struct{}, concurrentSchemas)
+	loaded := make([]*schemaInfo, len(schemas))
+	for i, schema := range schemas {
+		loading <- struct{}{}
+		c.run("loading database "+schema, func() error {
+			defer func() { <-loading }()
+			var warnings []string
+			loaded[i], warnings = getSchema(ctx, dbh.forSchema(schema), cfg.Sample)
+			for _, warning := range warnings {
+				c.warn(warning)
+			}
+			return nil
+		})
 	}
+	dbInfo.Warnings = c.wait()
+
+	// tables are only qualified by their schema when more than one schema can be collected
+	qualify := cfg.AllDatabases || len(schemas) > 1
 
-	for tableName := range tableMap {
-		tableInfo = append(tableInfo, tableMap[tableName])
+	var tableInfo []*TableInfo
+	for i, schema := range schemas {
+		for _, ti := range loaded[i].tables {
+			if qualify {
+				ti.Schema = schema
+			}
+			tableInfo = append(tableInfo, ti)
+		}
+		objects := loaded[i].objects
+		if qualify {
+			objects.setSchema(schema)
+		}
+		dbInfo.Routines = append(dbInfo.Routines, objects.routines...)
+		dbInfo.Triggers = append(dbInfo.Triggers, objects.triggers...)
+		dbInfo.Events = append(dbInfo.Events, objects.events...)
+		dbInfo.Views = append(dbInfo.Views, objects.views...)
 	}
 	sort.Slice(tableInfo, func(i, j int) bool {
+		if tableInfo[i].Schema != tableInfo[j].Schema {
+			return tableInfo[i].Schema < tableInfo[j].Schema
+		}
 		return tableInfo[i].Name < tableInfo[j].Name
 	})
 
-	dbInfo := &Info{
-		FileType:        "dbinfo",
-		Tables:          tableInfo,
-		GlobalVariables: globalVariables,
-	}
+	dbInfo.Tables = tableInfo
 	return dbInfo, nil
 }
 
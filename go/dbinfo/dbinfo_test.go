@@ -102,15 +102,22 @@ func TestDBInfoGet(t *testing.T) {
 	dbh := NewDBHelper(&cp)
 
 	t.Run("table sizes", func(t *testing.T) {
-		ts, err := dbh.getTableSizes()
+		ts, err := dbh.getTableSizes(context.Background())
 		require.NoError(t, err)
 		require.Len(t, ts, 16)
-		require.Equal(t, 6, ts["language"])
-		require.Equal(t, 1000, ts["film"])
+		require.Equal(t, 6, ts["language"].rows)
+		require.Equal(t, 1000, ts["film"].rows)
+	})
+
+	t.Run("schemas", func(t *testing.T) {
+		schemas, err := dbh.getSchemas(context.Background())
+		require.NoError(t, err)
+		require.Contains(t, schemas, "sakila")
+		require.NotContains(t, schemas, "mysql")
 	})
 
 	t.Run("column info", func(t *testing.T) {
-		tc, err := dbh.getColumnInfo()
+		tc, err := dbh.getColumnInfo(context.Background())
 		require.NoError(t, err)
 		require.Len(t, tc, 16)
 
@@ -141,13 +148,13 @@ func TestDBInfoGet(t *testing.T) {
 	})
 
 	t.Run("global variables", func(t *testing.T) {
-		gv, err := dbh.getGlobalVariables()
+		gv, err := dbh.getGlobalVariables(context.Background(), DefaultGlobalVariables())
 		require.NoError(t, err)
 		require.NotEmpty(t, gv)
 	})
 
 	t.Run("primary keys", func(t *testing.T) {
-		pks, err := dbh.getPrimaryKeys()
+		pks, err := dbh.getPrimaryKeys(context.Background())
 		require.NoError(t, err)
 		require.Len(t, pks, 16)
 		want := map[string][]string{
@@ -165,7 +172,7 @@ func TestDBInfoGet(t *testing.T) {
 	})
 
 	t.Run("indexes", func(t *testing.T) {
-		idxs, err := dbh.getIndexes()
+		idxs, err := dbh.getIndexes(context.Background())
 		require.NoError(t, err)
 		require.Len(t, idxs, 16)
 		idx, ok := idxs["film_actor"]
@@ -183,7 +190,7 @@ func TestDBInfoGet(t *testing.T) {
 	})
 
 	t.Run("foreign keys", func(t *testing.T) {
-		fks, err := dbh.getForeignKeys()
+		fks, err := dbh.getForeignKeys(context.Background())
 		require.NoError(t, err)
 		require.Len(t, fks, 11)
 		fk, ok := fks["city"]
@@ -207,3 +214,8 @@ func TestDBInfoGet(t *testing.T) {
 		require.Equal(t, "staff_id", fk[1].ReferencedColumnName)
 	})
 }
+
+func TestDBInfoGetNoDatabase(t *testing.T) {
+	_, err := Get(context.Background(), Config{})
+	require.ErrorIs(t, err, ErrNoDatabase)
+}
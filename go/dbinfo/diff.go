@@ -0,0 +1,311 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dbinfo
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/vitessio/vt/go/data"
+)
+
+// DefaultMinRowGrowth is the relative change in row count a table needs to show in a diff
+const DefaultMinRowGrowth = 0.2
+
+// minRowChange keeps small tables out of the row growth of a diff, going from 10 to 100 rows is not drift
+const minRowChange = 1000
+
+type (
+	// Diff is the schema drift between two dbinfo files, like snapshots taken before and after a migration
+	Diff struct {
+		FileType      string       `json:"fileType"`
+		Old           string       `json:"old"`
+		New           string       `json:"new"`
+		AddedTables   []string     `json:"addedTables,omitempty"`
+		RemovedTables []string     `json:"removedTables,omitempty"`
+		ChangedTables []*TableDiff `json:"changedTables,omitempty"`
+	}
+
+	// TableDiff is how a table present in both dbinfo files changed
+	TableDiff struct {
+		Table              string    `json:"table"`
+		AddedColumns       []string  `json:"addedColumns,omitempty"`
+		RemovedColumns     []string  `json:"removedColumns,omitempty"`
+		ChangedColumns     []*Change `json:"changedColumns,omitempty"`
+		AddedIndexes       []string  `json:"addedIndexes,omitempty"`
+		RemovedIndexes     []string  `json:"removedIndexes,omitempty"`
+		ChangedIndexes     []*Change `json:"changedIndexes,omitempty"`
+		AddedForeignKeys   []string  `json:"addedForeignKeys,omitempty"`
+		RemovedForeignKeys []string  `json:"removedForeignKeys,omitempty"`
+		// RowGrowth is only set when the row count changed significantly
+		RowGrowth *RowGrowth `json:"rowGrowth,omitempty"`
+	}
+
+	// Change is a column or index with the same name but a different definition
+	Change struct {
+		Name string `json:"name"`
+		Old  string `json:"old"`
+		New  string `json:"new"`
+	}
+
+	RowGrowth struct {
+		Old int `json:"old"`
+		New int `json:"new"`
+	}
+
+	DiffConfig struct {
+		OldFile string
+		NewFile string
+		// MinRowGrowth is the relative change in row count a table needs to be reported, like 0.2 for 20%
+		MinRowGrowth float64
+	}
+)
+
+// Ratio is the relative change of the row count, like 1.5 when the table grew by 150%
+func (rg *RowGrowth) Ratio() float64 {
+	if rg.Old == 0 {
+		return 0
+	}
+	return float64(rg.New-rg.Old) / float64(rg.Old)
+}
+
+func (td *TableDiff) empty() bool {
+	return len(td.AddedColumns) == 0 && len(td.RemovedColumns) == 0 && len(td.ChangedColumns) == 0 &&
+		len(td.AddedIndexes) == 0 && len(td.RemovedIndexes) == 0 && len(td.ChangedIndexes) == 0 &&
+		len(td.AddedForeignKeys) == 0 && len(td.RemovedForeignKeys) == 0 && td.RowGrowth == nil
+}
+
+func RunDiff(cfg DiffConfig) error {
+	return runDiff(os.Stdout, cfg)
+}
+
+func runDiff(out io.Writer, cfg DiffConfig) error {
+	oldInfo, err := Load(cfg.OldFile)
+	if err != nil {
+		return fmt.Errorf("error reading %s: %w", cfg.OldFile, err)
+	}
+	newInfo, err := Load(cfg.NewFile)
+	if err != nil {
+		return fmt.Errorf("error reading %s: %w", cfg.NewFile, err)
+	}
+
+	d := Compare(oldInfo, newInfo, cfg.MinRowGrowth)
+	d.Old = cfg.OldFile
+	d.New = cfg.NewFile
+	b, err := json.MarshalIndent(d, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = out.Write(b)
+	return err
+}
+
+// Compare lists the tables, columns, indexes and foreign keys that were added, removed or changed
+// from oldInfo to newInfo, and the tables whose row count changed by at least minRowGrowth
+func Compare(oldInfo, newInfo *Info, minRowGrowth float64) *Diff {
+	d := &Diff{FileType: "dbinfoDiff"}
+	oldTables := tablesByName(oldInfo)
+	newTables := tablesByName(newInfo)
+
+	for name, nt := range newTables {
+		ot, ok := oldTables[name]
+		if !ok {
+			d.AddedTables = append(d.AddedTables, name)
+			continue
+		}
+		if td := compareTables(name, ot, nt, minRowGrowth); !td.empty() {
+			d.ChangedTables = append(d.ChangedTables, td)
+		}
+	}
+	for name := range oldTables {
+		if _, ok := newTables[name]; !ok {
+			d.RemovedTables = append(d.RemovedTables, name)
+		}
+	}
+
+	sort.Strings(d.AddedTables)
+	sort.Strings(d.RemovedTables)
+	sort.Slice(d.ChangedTables, func(i, j int) bool {
+		return d.ChangedTables[i].Table < d.ChangedTables[j].Table
+	})
+	return d
+}
+
+func tablesByName(info *Info) map[string]*TableInfo {
+	tables := make(map[string]*TableInfo, len(info.Tables))
+	for _, ti := range info.Tables {
+		tables[ti.QualifiedName()] = ti
+	}
+	return tables
+}
+
+func compareTables(name string, ot, nt *TableInfo, minRowGrowth float64) *TableDiff {
+	td := &TableDiff{Table: name}
+
+	oldColumns := make(map[string]string, len(ot.Columns))
+	for _, col := range ot.Columns {
+		oldColumns[col.Name] = describeColumn(col)
+	}
+	newColumns := make(map[string]string, len(nt.Columns))
+	for _, col := range nt.Columns {
+		newColumns[col.Name] = describeColumn(col)
+	}
+	td.AddedColumns, td.RemovedColumns, td.ChangedColumns = compareDefinitions(oldColumns, newColumns)
+
+	td.AddedIndexes, td.RemovedIndexes, td.ChangedIndexes = compareDefinitions(indexDefinitions(ot), indexDefinitions(nt))
+
+	oldFKs, newFKs := foreignKeyDefinitions(ot), foreignKeyDefinitions(nt)
+	for fk := range newFKs {
+		if !oldFKs[fk] {
+			td.AddedForeignKeys = append(td.AddedForeignKeys, fk)
+		}
+	}
+	for fk := range oldFKs {
+		if !newFKs[fk] {
+			td.RemovedForeignKeys = append(td.RemovedForeignKeys, fk)
+		}
+	}
+	sort.Strings(td.AddedForeignKeys)
+	sort.Strings(td.RemovedForeignKeys)
+
+	if significantRowChange(ot.Rows, nt.Rows, minRowGrowth) {
+		td.RowGrowth = &RowGrowth{Old: ot.Rows, New: nt.Rows}
+	}
+	return td
+}
+
+// compareDefinitions compares named definitions, returning the added and removed names
+// and the names whose definition changed
+func compareDefinitions(oldDefs, newDefs map[string]string) (added, removed []string, changed []*Change) {
+	for name, def := range newDefs {
+		oldDef, ok := oldDefs[name]
+		switch {
+		case !ok:
+			added = append(added, name)
+		case oldDef != def:
+			changed = append(changed, &Change{Name: name, Old: oldDef, New: def})
+		}
+	}
+	for name := range oldDefs {
+		if _, ok := newDefs[name]; !ok {
+			removed = append(removed, name)
+		}
+	}
+	sort.Strings(added)
+	sort.Strings(removed)
+	sort.Slice(changed, func(i, j int) bool {
+		return changed[i].Name < changed[j].Name
+	})
+	return added, removed, changed
+}
+
+// describeColumn is the part of a column definition a diff compares, like `varchar(45) not null collate utf8mb4_general_ci`
+func describeColumn(col *TableColumn) string {
+	typ := col.ColumnType
+	if typ == "" {
+		typ = col.Type
+	}
+	parts := []string{typ}
+	if col.IsNullable {
+		parts = append(parts, "null")
+	} else {
+		parts = append(parts, "not null")
+	}
+	if col.Default != nil {
+		parts = append(parts, "default "+*col.Default)
+	}
+	if col.Extra != "" {
+		parts = append(parts, col.Extra)
+	}
+	if col.Collation != "" {
+		parts = append(parts, "collate "+col.Collation)
+	}
+	return strings.Join(parts, " ")
+}
+
+// indexDefinitions has the definitions of the primary key and the indexes of a table, like `unique (email)`
+func indexDefinitions(ti *TableInfo) map[string]string {
+	defs := make(map[string]string, len(ti.Indexes)+1)
+	if ti.PrimaryKey != nil {
+		defs["PRIMARY"] = "primary key (" + strings.Join(ti.PrimaryKey.Columns, ", ") + ")"
+	}
+	for _, idx := range ti.Indexes {
+		if idx.Name == "PRIMARY" {
+			// the primary key is listed with the indexes too, it is compared as the primary key
+			continue
+		}
+		def := "(" + strings.Join(idx.Columns, ", ") + ")"
+		if !idx.NonUnique {
+			def = "unique " + def
+		}
+		defs[idx.Name] = def
+	}
+	return defs
+}
+
+// foreignKeyDefinitions has the foreign keys of a table, like `fk_customer (customer_id) references customer(id)`
+func foreignKeyDefinitions(ti *TableInfo) map[string]bool {
+	defs := make(map[string]bool, len(ti.ForeignKeys))
+	for _, fk := range ti.ForeignKeys {
+		referenced := fk.ReferencedTableName
+		if fk.ReferencedSchemaName != "" {
+			referenced = fk.ReferencedSchemaName + "." + referenced
+		}
+		defs[fmt.Sprintf("%s (%s) references %s(%s)", fk.ConstraintName, fk.ColumnName, referenced, fk.ReferencedColumnName)] = true
+	}
+	return defs
+}
+
+func significantRowChange(oldRows, newRows int, minRowGrowth float64) bool {
+	change := newRows - oldRows
+	if change < 0 {
+		change = -change
+	}
+	if change < minRowChange {
+		return false
+	}
+	if oldRows == 0 {
+		return true
+	}
+	return float64(change)/float64(oldRows) >= minRowGrowth
+}
+
+func ReadDiffFile(fileName string) (*Diff, error) {
+	typ, err := data.GetFileType(fileName)
+	if err != nil {
+		return nil, err
+	}
+	if typ != data.DBInfoDiffFile {
+		return nil, errors.New("file is not a dbinfo diff file")
+	}
+
+	b, err := os.ReadFile(fileName)
+	if err != nil {
+		return nil, err
+	}
+	var d Diff
+	err = json.Unmarshal(b, &d)
+	if err != nil {
+		return nil, err
+	}
+	return &d, nil
+}
@@ -0,0 +1,84 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dbinfo
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompare(t *testing.T) {
+	oldInfo, err := Load("../testdata/dbInfo-output/shop-before-dbinfo.json")
+	require.NoError(t, err)
+	newInfo, err := Load("../testdata/dbInfo-output/shop-after-dbinfo.json")
+	require.NoError(t, err)
+
+	d := Compare(oldInfo, newInfo, DefaultMinRowGrowth)
+	assert.Equal(t, []string{"payments"}, d.AddedTables)
+	assert.Equal(t, []string{"settings"}, d.RemovedTables)
+	require.Len(t, d.ChangedTables, 2)
+
+	customers := d.ChangedTables[0]
+	assert.Equal(t, "customers", customers.Table)
+	assert.Equal(t, []string{"created_at"}, customers.AddedColumns)
+	assert.Equal(t, []string{"fax"}, customers.RemovedColumns)
+	assert.Equal(t, []*Change{{Name: "idx_email", Old: "(email)", New: "unique (email)"}}, customers.ChangedIndexes)
+	// 500 more rows is below the minimum change
+	assert.Nil(t, customers.RowGrowth)
+
+	orders := d.ChangedTables[1]
+	assert.Equal(t, "orders", orders.Table)
+	assert.Equal(t, []*Change{{Name: "id", Old: "int not null auto_increment", New: "bigint not null auto_increment"}}, orders.ChangedColumns)
+	assert.Equal(t, []string{"idx_status"}, orders.AddedIndexes)
+	assert.Equal(t, []*Change{{Name: "PRIMARY", Old: "primary key (id)", New: "primary key (customer_id, id)"}}, orders.ChangedIndexes)
+	assert.Equal(t, []string{"fk_orders_customer (customer_id) references customers(id)"}, orders.AddedForeignKeys)
+	assert.Equal(t, &RowGrowth{Old: 120000, New: 310000}, orders.RowGrowth)
+
+	// order_audit grew by 20,000 rows, which is not 20% of its size
+	d = Compare(oldInfo, newInfo, 0.01)
+	require.Len(t, d.ChangedTables, 3)
+	assert.Equal(t, "order_audit", d.ChangedTables[1].Table)
+
+	assert.Empty(t, Compare(oldInfo, oldInfo, DefaultMinRowGrowth).ChangedTables)
+}
+
+func TestRunDiff(t *testing.T) {
+	sb := &strings.Builder{}
+	err := runDiff(sb, DiffConfig{
+		OldFile:      "../testdata/dbInfo-output/shop-before-dbinfo.json",
+		NewFile:      "../testdata/dbInfo-output/shop-after-dbinfo.json",
+		MinRowGrowth: DefaultMinRowGrowth,
+	})
+	require.NoError(t, err)
+
+	var got Diff
+	require.NoError(t, json.Unmarshal([]byte(sb.String()), &got))
+	assert.Equal(t, "../testdata/dbInfo-output/shop-before-dbinfo.json", got.Old)
+
+	// the expected diff was made from the go directory
+	expected, err := ReadDiffFile("../testdata/dbInfo-output/shop-dbinfo-diff.json")
+	require.NoError(t, err)
+	got.Old, got.New = expected.Old, expected.New
+	assert.Equal(t, expected, &got)
+
+	_, err = ReadDiffFile("../testdata/dbInfo-output/shop-before-dbinfo.json")
+	require.ErrorContains(t, err, "file is not a dbinfo diff file")
+}
@@ -0,0 +1,463 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dbinfo
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"vitess.io/vitess/go/mysql/collations"
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/sqlparser"
+)
+
+// sqlSchema collects the tables and views of a schema dump
+type sqlSchema struct {
+	// current is the schema of the last USE statement
+	current string
+	schemas map[string]bool
+	tables  []*TableInfo
+	views   map[string]*View
+	// warnings are the CREATE TABLE and CREATE VIEW statements that could not be parsed
+	warnings []string
+}
+
+// FromSQL builds the dbinfo of the CREATE TABLE and CREATE VIEW statements of a schema dump,
+// like the output of mysqldump --no-data, without a database.
+// Row counts are read from a CSV file of table names and row counts when one is given.
+func FromSQL(fileName, rowCountsFile string) (*Info, error) {
+	b, err := os.ReadFile(fileName)
+	if err != nil {
+		return nil, err
+	}
+
+	parser := sqlparser.NewTestParser()
+	pieces, err := parser.SplitStatementToPieces(string(b))
+	if err != nil {
+		return nil, fmt.Errorf("error splitting %s: %w", fileName, err)
+	}
+
+	ss := &sqlSchema{schemas: make(map[string]bool), views: make(map[string]*View)}
+	for _, piece := range pieces {
+		stmt, err := parser.Parse(piece)
+		if ddl, ok := stmt.(sqlparser.DDLStatement); ok && !ddl.IsFullyParsed() {
+			// the parser falls back to the name of the table when it does not understand the definition
+			err = errors.New("the definition could not be fully parsed")
+		}
+		if err != nil {
+			// dumps contain statements the parser does not know, like SET or LOCK TABLES with options,
+			// but a table or view that cannot be parsed is missing from the output
+			if createsTableOrView(piece) {
+				firstLine, _, _ := strings.Cut(strings.TrimSpace(piece), "\n")
+				ss.warnings = append(ss.warnings, fmt.Sprintf("skipping %q: %v", firstLine, err))
+			}
+			continue
+		}
+		ss.add(stmt)
+	}
+
+	if rowCountsFile != "" {
+		if err := ss.setRowCounts(rowCountsFile); err != nil {
+			return nil, err
+		}
+	}
+	return ss.info(), nil
+}
+
+// createsTableOrView tells if a statement is a CREATE TABLE or CREATE VIEW, also in a versioned comment like mysqldump writes them
+func createsTableOrView(piece string) bool {
+	words := strings.Fields(strings.ToUpper(piece))
+	if len(words) > 0 && strings.HasPrefix(words[0], "/*!") {
+		words = words[1:]
+	}
+	if len(words) == 0 || words[0] != "CREATE" {
+		return false
+	}
+	for _, word := range words[1:] {
+		switch {
+		case word == "TABLE", word == "VIEW":
+			return true
+		case word == "AS", word == "ON", strings.HasPrefix(word, "("),
+			word == "DATABASE", word == "SCHEMA", word == "INDEX", word == "TRIGGER",
+			word == "PROCEDURE", word == "FUNCTION", word == "EVENT":
+			return false
+		}
+	}
+	return false
+}
+
+func (ss *sqlSchema) add(stmt sqlparser.Statement) {
+	switch stmt := stmt.(type) {
+	case *sqlparser.Use:
+		ss.current = stmt.DBName.String()
+	case *sqlparser.CreateTable:
+		if stmt.TableSpec == nil {
+			return
+		}
+		ti := tableFromSQL(stmt.TableSpec)
+		ti.Name = stmt.Table.Name.String()
+		ti.Schema = ss.schemaOf(stmt.Table)
+		for _, fk := range ti.ForeignKeys {
+			// like information_schema, the schema of the referenced table is only kept when it is another one
+			if fk.ReferencedSchemaName == ti.Schema {
+				fk.ReferencedSchemaName = ""
+			}
+		}
+		ss.tables = append(ss.tables, ti)
+	case *sqlparser.CreateView:
+		view := &View{
+			Name:       stmt.ViewName.Name.String(),
+			Schema:     ss.schemaOf(stmt.ViewName),
+			Definition: sqlparser.String(stmt.Select),
+		}
+		// mysqldump creates a placeholder for each view before the real definition, the last one wins
+		ss.views[view.Schema+"."+view.Name] = view
+	}
+}
+
+func (ss *sqlSchema) schemaOf(name sqlparser.TableName) string {
+	schema := ss.current
+	if name.Qualifier.NotEmpty() {
+		schema = name.Qualifier.String()
+	}
+	ss.schemas[schema] = true
+	return schema
+}
+
+// info sorts the tables and views, they are only qualified by their schema when the dump has several schemas
+func (ss *sqlSchema) info() *Info {
+	qualify := len(ss.schemas) > 1
+	views := make([]*View, 0, len(ss.views))
+	for _, view := range ss.views {
+		views = append(views, view)
+	}
+	for _, ti := range ss.tables {
+		if !qualify {
+			ti.Schema = ""
+		}
+	}
+	for _, view := range views {
+		if !qualify {
+			view.Schema = ""
+		}
+	}
+	sort.Slice(ss.tables, func(i, j int) bool {
+		return ss.tables[i].QualifiedName() < ss.tables[j].QualifiedName()
+	})
+	sort.Slice(views, func(i, j int) bool {
+		return qualifiedViewName(views[i]) < qualifiedViewName(views[j])
+	})
+	return &Info{
+		FileType:        "dbinfo",
+		Tables:          ss.tables,
+		Views:           views,
+		GlobalVariables: map[string]string{},
+		Warnings:        ss.warnings,
+	}
+}
+
+func qualifiedViewName(view *View) string {
+	if view.Schema == "" {
+		return view.Name
+	}
+	return view.Schema + "." + view.Name
+}
+
+// setRowCounts reads a CSV file of table names, qualified by their schema or not, and row counts.
+// A first line that does not have a number of rows is taken as a header.
+func (ss *sqlSchema) setRowCounts(fileName string) error {
+	f, err := os.Open(fileName)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	r := csv.NewReader(f)
+	r.FieldsPerRecord = 2
+	r.TrimLeadingSpace = true
+	for line := 1; ; line++ {
+		record, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("error reading row counts from %s: %w", fileName, err)
+		}
+		rows, err := strconv.Atoi(record[1])
+		if err != nil {
+			if line == 1 {
+				continue
+			}
+			return fmt.Errorf("invalid row count %q for table %s in %s line %d", record[1], record[0], fileName, line)
+		}
+		schema, table, qualified := strings.Cut(record[0], ".")
+		if !qualified {
+			schema, table = "", record[0]
+		}
+		for _, ti := range ss.tables {
+			// a qualified name matches the tables of a dump without schema as well
+			if strings.EqualFold(table, ti.Name) && (schema == "" || ti.Schema == "" || strings.EqualFold(schema, ti.Schema)) {
+				ti.Rows = rows
+			}
+		}
+	}
+}
+
+// tableFromSQL builds the table info from its CREATE TABLE definition, the way information_schema describes it
+func tableFromSQL(spec *sqlparser.TableSpec) *TableInfo {
+	ti := &TableInfo{}
+	var charset, collation string
+	for _, opt := range spec.Options {
+		switch strings.ToUpper(opt.Name) {
+		case "ENGINE":
+			ti.Engine = opt.String
+		case "AUTO_INCREMENT":
+			if opt.Value != nil {
+				ti.AutoIncrement, _ = strconv.ParseUint(opt.Value.Val, 10, 64)
+			}
+		case "CHARSET", "CHARACTER SET":
+			charset = opt.String
+		case "COLLATE":
+			collation = opt.String
+		}
+	}
+
+	ti.Indexes = indexesFromSQL(spec)
+	for _, idx := range ti.Indexes {
+		if idx.Name == "PRIMARY" {
+			ti.PrimaryKey = &PrimaryKey{Columns: idx.Columns}
+		}
+	}
+	for _, col := range spec.Columns {
+		ti.Columns = append(ti.Columns, columnFromSQL(col, charset, collation, ti))
+	}
+	ti.ForeignKeys = foreignKeysFromSQL(spec)
+	return ti
+}
+
+// indexesFromSQL lists the indexes of the table, including the ones declared on a column
+func indexesFromSQL(spec *sqlparser.TableSpec) []*Index {
+	var indexes []*Index
+	for _, def := range spec.Indexes {
+		idx := &Index{Name: def.Info.Name.String(), NonUnique: !def.Info.IsUnique()}
+		if def.Info.Type == sqlparser.IndexTypePrimary {
+			idx.Name = "PRIMARY"
+		}
+		for _, col := range def.Columns {
+			if col.Expression == nil {
+				idx.Columns = append(idx.Columns, col.Column.String())
+			}
+		}
+		if idx.Name == "" && len(idx.Columns) > 0 {
+			idx.Name = idx.Columns[0]
+		}
+		indexes = append(indexes, idx)
+	}
+	for _, col := range spec.Columns {
+		if col.Type.Options == nil {
+			continue
+		}
+		switch col.Type.Options.KeyOpt {
+		case sqlparser.ColKeyPrimary, sqlparser.ColKey:
+			indexes = append(indexes, &Index{Name: "PRIMARY", Columns: []string{col.Name.String()}})
+		case sqlparser.ColKeyUnique, sqlparser.ColKeyUniqueKey:
+			indexes = append(indexes, &Index{Name: col.Name.String(), Columns: []string{col.Name.String()}})
+		default:
+		}
+	}
+	return indexes
+}
+
+// columnFromSQL describes a column like information_schema.columns, with the charset and collation
+// of the table for text columns that do not have their own
+func columnFromSQL(def *sqlparser.ColumnDefinition, charset, collation string, ti *TableInfo) *TableColumn {
+	ct := def.Type
+	col := &TableColumn{
+		Name:       def.Name.String(),
+		Type:       strings.ToLower(ct.Type),
+		ColumnType: columnType(ct),
+		KeyType:    keyType(def.Name.String(), ti),
+		IsNullable: true,
+	}
+	opts := ct.Options
+	if sqltypes.IsText(ct.SQLType()) || col.Type == "enum" || col.Type == "set" {
+		if ct.Charset.Name != "" {
+			charset, collation = ct.Charset.Name, ""
+		}
+		if opts != nil && opts.Collate != "" {
+			if ct.Charset.Name == "" {
+				charset = ""
+			}
+			collation = opts.Collate
+		}
+		col.Charset, col.Collation = charsetAndCollation(charset, collation)
+	}
+
+	if col.KeyType == "pri" {
+		col.IsNullable = false
+	}
+	if opts == nil {
+		return col
+	}
+	if opts.Null != nil {
+		col.IsNullable = *opts.Null
+	}
+	col.Extra = columnExtra(opts)
+	if opts.As != nil {
+		col.GenerationExpression = sqlparser.String(opts.As)
+	}
+	if _, isNull := opts.Default.(*sqlparser.NullVal); opts.Default != nil && !isNull {
+		def := exprString(opts.Default)
+		switch expr := opts.Default.(type) {
+		case *sqlparser.Literal:
+			def = expr.Val
+		case *sqlparser.CurTimeFuncExpr:
+			// information_schema has CURRENT_TIMESTAMP in upper case for the default
+			def = strings.ToUpper(def)
+		}
+		col.Default = &def
+	}
+	return col
+}
+
+// charsetAndCollation fills in the default collation of a charset, or the charset of a collation, for MySQL 8.0
+func charsetAndCollation(charset, collation string) (string, string) {
+	env := collations.MySQL8()
+	switch {
+	case charset != "" && collation == "":
+		if id := env.DefaultCollationForCharset(charset); id != collations.Unknown {
+			collation = env.LookupName(id)
+		}
+	case charset == "" && collation != "":
+		if id := env.LookupByName(collation); id != collations.Unknown {
+			charset = env.LookupCharsetName(id)
+		}
+	}
+	return charset, collation
+}
+
+func columnType(ct *sqlparser.ColumnType) string {
+	typ := strings.ToLower(ct.Type)
+	switch {
+	case len(ct.EnumValues) > 0:
+		typ += "(" + strings.Join(ct.EnumValues, ",") + ")"
+	case ct.Length != nil && ct.Scale != nil:
+		typ += fmt.Sprintf("(%d,%d)", *ct.Length, *ct.Scale)
+	case ct.Length != nil:
+		typ += fmt.Sprintf("(%d)", *ct.Length)
+	}
+	if ct.Unsigned {
+		typ += " unsigned"
+	}
+	if ct.Zerofill {
+		typ += " zerofill"
+	}
+	return typ
+}
+
+func columnExtra(opts *sqlparser.ColumnTypeOptions) string {
+	var extra []string
+	if opts.Autoincrement {
+		extra = append(extra, "auto_increment")
+	}
+	if _, now := opts.Default.(*sqlparser.CurTimeFuncExpr); now || (opts.Default != nil && !opts.DefaultLiteral) {
+		extra = append(extra, "default_generated")
+	}
+	if opts.OnUpdate != nil {
+		extra = append(extra, "on update "+exprString(opts.OnUpdate))
+	}
+	if opts.As != nil {
+		if opts.Storage == sqlparser.StoredStorage {
+			extra = append(extra, "stored generated")
+		} else {
+			extra = append(extra, "virtual generated")
+		}
+	}
+	return strings.Join(extra, " ")
+}
+
+// exprString formats an expression, with CURRENT_TIMESTAMP and the like without parentheses like MySQL does
+func exprString(expr sqlparser.Expr) string {
+	now, ok := expr.(*sqlparser.CurTimeFuncExpr)
+	if !ok {
+		return sqlparser.String(expr)
+	}
+	name := now.Name.Lowered()
+	if name == "now" || name == "localtime" || name == "localtimestamp" {
+		name = "current_timestamp"
+	}
+	if now.Fsp > 0 {
+		name += fmt.Sprintf("(%d)", now.Fsp)
+	}
+	return name
+}
+
+// keyType is pri for the columns of the primary key, and uni or mul for the first column of a unique or other index
+func keyType(column string, ti *TableInfo) string {
+	if ti.PrimaryKey != nil {
+		for _, pk := range ti.PrimaryKey.Columns {
+			if strings.EqualFold(pk, column) {
+				return "pri"
+			}
+		}
+	}
+	key := ""
+	for _, idx := range ti.Indexes {
+		if len(idx.Columns) == 0 || !strings.EqualFold(idx.Columns[0], column) {
+			continue
+		}
+		if !idx.NonUnique && len(idx.Columns) == 1 {
+			return "uni"
+		}
+		key = "mul"
+	}
+	return key
+}
+
+func foreignKeysFromSQL(spec *sqlparser.TableSpec) []*ForeignKey {
+	var fks []*ForeignKey
+	for _, constraint := range spec.Constraints {
+		def, ok := constraint.Details.(*sqlparser.ForeignKeyDefinition)
+		if !ok {
+			continue
+		}
+		name := constraint.Name.String()
+		if name == "" {
+			name = def.IndexName.String()
+		}
+		ref := def.ReferenceDefinition
+		for i, col := range def.Source {
+			if i >= len(ref.ReferencedColumns) {
+				break
+			}
+			fks = append(fks, &ForeignKey{
+				ColumnName:           col.String(),
+				ConstraintName:       name,
+				ReferencedSchemaName: ref.ReferencedTable.Qualifier.String(),
+				ReferencedTableName:  ref.ReferencedTable.Name.String(),
+				ReferencedColumnName: ref.ReferencedColumns[i].String(),
+			})
+		}
+	}
+	return fks
+}
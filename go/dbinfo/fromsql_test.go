@@ -0,0 +1,140 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dbinfo
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFromSQL(t *testing.T) {
+	si, err := FromSQL("../testdata/query-logs/sakila-schema-ddls.sql", "../testdata/sakila-row-counts.csv")
+	require.NoError(t, err)
+	require.Len(t, si.Tables, 16)
+	assert.Empty(t, si.Warnings)
+
+	tables := make(map[string]*TableInfo)
+	for _, table := range si.Tables {
+		assert.Empty(t, table.Schema)
+		tables[table.Name] = table
+	}
+	assert.Equal(t, 200, tables["actor"].Rows)
+	assert.Equal(t, 1000, tables["film"].Rows)
+	assert.Equal(t, 16044, tables["rental"].Rows)
+	assert.Equal(t, 0, tables["payment"].Rows)
+
+	actor := tables["actor"]
+	assert.Equal(t, "InnoDB", actor.Engine)
+	assert.Equal(t, &PrimaryKey{Columns: []string{"actor_id"}}, actor.PrimaryKey)
+	assert.Equal(t, []*Index{
+		{Name: "PRIMARY", Columns: []string{"actor_id"}},
+		{Name: "idx_actor_last_name", Columns: []string{"last_name"}, NonUnique: true},
+	}, actor.Indexes)
+	assert.Equal(t, &TableColumn{Name: "actor_id", Type: "smallint", ColumnType: "smallint unsigned", KeyType: "pri", Extra: "auto_increment"}, actor.Columns[0])
+	assert.Equal(t, &TableColumn{
+		Name: "last_name", Type: "varchar", ColumnType: "varchar(45)", KeyType: "mul",
+		Charset: "utf8mb4", Collation: "utf8mb4_0900_ai_ci",
+	}, actor.Columns[2])
+	lastUpdate := actor.Columns[3]
+	assert.Equal(t, "default_generated on update current_timestamp", lastUpdate.Extra)
+	require.NotNil(t, lastUpdate.Default)
+	assert.Equal(t, "CURRENT_TIMESTAMP", *lastUpdate.Default)
+
+	assert.Equal(t, &PrimaryKey{Columns: []string{"film_id", "category_id"}}, tables["film_category"].PrimaryKey)
+	assert.Contains(t, tables["film"].ForeignKeys, &ForeignKey{
+		ColumnName:           "language_id",
+		ConstraintName:       "fk_film_language",
+		ReferencedTableName:  "language",
+		ReferencedColumnName: "language_id",
+	})
+}
+
+func TestFromSQLSchemas(t *testing.T) {
+	dump := filepath.Join(t.TempDir(), "dump.sql")
+	err := os.WriteFile(dump, []byte(`
+CREATE DATABASE shop;
+USE shop;
+CREATE TABLE orders (
+  id bigint NOT NULL,
+  customer_id bigint,
+  status varchar(10) CHARACTER SET latin1,
+  PRIMARY KEY (id),
+  CONSTRAINT fk_customer FOREIGN KEY (customer_id) REFERENCES billing.customers (id)
+) ENGINE=InnoDB AUTO_INCREMENT=42 DEFAULT CHARSET=utf8mb4;
+/*!50001 CREATE VIEW open_orders AS SELECT 1 AS id */;
+/*!50001 CREATE VIEW open_orders AS SELECT id FROM orders WHERE status = 'open' */;
+CREATE TABLE billing.customers (id bigint PRIMARY KEY, email varchar(100) UNIQUE);
+`), 0o600)
+	require.NoError(t, err)
+
+	si, err := FromSQL(dump, "")
+	require.NoError(t, err)
+	require.Len(t, si.Tables, 2)
+
+	customers, orders := si.Tables[0], si.Tables[1]
+	assert.Equal(t, "billing.customers", customers.QualifiedName())
+	assert.Equal(t, &PrimaryKey{Columns: []string{"id"}}, customers.PrimaryKey)
+	assert.Equal(t, "uni", customers.Columns[1].KeyType)
+
+	assert.Equal(t, "shop.orders", orders.QualifiedName())
+	assert.Equal(t, uint64(42), orders.AutoIncrement)
+	assert.Equal(t, "billing", orders.ForeignKeys[0].ReferencedSchemaName)
+	assert.Equal(t, "latin1", orders.Columns[2].Charset)
+	assert.Equal(t, "latin1_swedish_ci", orders.Columns[2].Collation)
+
+	require.Len(t, si.Views, 1)
+	assert.Equal(t, "shop", si.Views[0].Schema)
+	assert.Equal(t, "select id from orders where `status` = 'open'", si.Views[0].Definition)
+	assert.Empty(t, si.Warnings)
+}
+
+func TestFromSQLUnparsableCreate(t *testing.T) {
+	dump := filepath.Join(t.TempDir(), "dump.sql")
+	err := os.WriteFile(dump, []byte(`
+SET @saved_cs_client = @@character_set_client NONSENSE;
+CREATE TABLE customers (id bigint PRIMARY KEY);
+CREATE TABLE orders (
+  id bigint NOT NULL,
+  amount decimal(10,2) WHATEVER
+);
+/*!50001 CREATE VIEW customer_ids AS SELECT id FROM customers ORDER BY */;
+`), 0o600)
+	require.NoError(t, err)
+
+	si, err := FromSQL(dump, "")
+	require.NoError(t, err)
+	require.Len(t, si.Tables, 1)
+	assert.Equal(t, "customers", si.Tables[0].Name)
+
+	// the SET statement is not reported, the table and the view that are missing are
+	require.Len(t, si.Warnings, 2)
+	assert.Contains(t, si.Warnings[0], `skipping "CREATE TABLE orders ("`)
+	assert.Contains(t, si.Warnings[1], `skipping "/*!50001 CREATE VIEW customer_ids`)
+}
+
+func TestCreatesTableOrView(t *testing.T) {
+	assert.True(t, createsTableOrView("CREATE TABLE t (id int)"))
+	assert.True(t, createsTableOrView("create temporary table t (id int)"))
+	assert.True(t, createsTableOrView("/*!50001 CREATE ALGORITHM=UNDEFINED DEFINER=`root`@`%` SQL SECURITY DEFINER VIEW v AS select 1 */"))
+	assert.False(t, createsTableOrView("CREATE INDEX idx ON t (id)"))
+	assert.False(t, createsTableOrView("CREATE DATABASE shop"))
+	assert.False(t, createsTableOrView("LOCK TABLES t WRITE"))
+}
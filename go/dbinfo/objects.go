@@ -0,0 +1,162 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dbinfo
+
+import (
+	"context"
+	"fmt"
+)
+
+type (
+	// Routine is a stored procedure or a stored function
+	Routine struct {
+		Name   string `json:"name"`
+		Schema string `json:"schema,omitempty"`
+		// Type is PROCEDURE or FUNCTION
+		Type string `json:"type"`
+		// DataAccess tells whether the routine reads or modifies data, like READS SQL DATA
+		DataAccess    string `json:"dataAccess,omitempty"`
+		Deterministic bool   `json:"deterministic,omitempty"`
+	}
+
+	Trigger struct {
+		Name   string `json:"name"`
+		Schema string `json:"schema,omitempty"`
+		// Table is the table the trigger is defined on
+		Table string `json:"table"`
+		// Event is INSERT, UPDATE or DELETE, and Timing is BEFORE or AFTER
+		Event  string `json:"event"`
+		Timing string `json:"timing"`
+	}
+
+	Event struct {
+		Name   string `json:"name"`
+		Schema string `json:"schema,omitempty"`
+		// Schedule is when the event runs, like EVERY 1 DAY or AT 2024-01-01 00:00:00
+		Schedule string `json:"schedule"`
+		Status   string `json:"status"`
+	}
+
+	View struct {
+		Name       string `json:"name"`
+		Schema     string `json:"schema,omitempty"`
+		Definition string `json:"definition"`
+		Updatable  bool   `json:"updatable,omitempty"`
+	}
+
+	// schemaObjects are the objects of a schema besides its tables
+	schemaObjects struct {
+		routines []*Routine
+		triggers []*Trigger
+		events   []*Event
+		views    []*View
+	}
+)
+
+// setSchema qualifies all the objects by the given schema
+func (so *schemaObjects) setSchema(schema string) {
+	for _, r := range so.routines {
+		r.Schema = schema
+	}
+	for _, t := range so.triggers {
+		t.Schema = schema
+	}
+	for _, e := range so.events {
+		e.Schema = schema
+	}
+	for _, v := range so.views {
+		v.Schema = schema
+	}
+}
+
+func (dbh *DBHelper) getRoutines(ctx context.Context) ([]*Routine, error) {
+	queryRoutines := "select routine_name, routine_type, sql_data_access, is_deterministic from information_schema.routines " +
+		"where routine_schema = %a order by routine_name"
+	qr, err := dbh.fetch(ctx, queryRoutines, dbh.vtParams.DbName)
+	if err != nil {
+		return nil, err
+	}
+	routines := make([]*Routine, 0, len(qr.Rows))
+	for _, row := range qr.Rows {
+		routines = append(routines, &Routine{
+			Name:          row[0].ToString(),
+			Type:          row[1].ToString(),
+			DataAccess:    row[2].ToString(),
+			Deterministic: row[3].ToString() == "YES",
+		})
+	}
+	return routines, nil
+}
+
+func (dbh *DBHelper) getTriggers(ctx context.Context) ([]*Trigger, error) {
+	queryTriggers := "select trigger_name, event_object_table, event_manipulation, action_timing from information_schema.triggers " +
+		"where trigger_schema = %a order by event_object_table, trigger_name"
+	qr, err := dbh.fetch(ctx, queryTriggers, dbh.vtParams.DbName)
+	if err != nil {
+		return nil, err
+	}
+	triggers := make([]*Trigger, 0, len(qr.Rows))
+	for _, row := range qr.Rows {
+		triggers = append(triggers, &Trigger{
+			Name:   row[0].ToString(),
+			Table:  row[1].ToString(),
+			Event:  row[2].ToString(),
+			Timing: row[3].ToString(),
+		})
+	}
+	return triggers, nil
+}
+
+func (dbh *DBHelper) getEvents(ctx context.Context) ([]*Event, error) {
+	queryEvents := "select event_name, event_type, interval_value, interval_field, execute_at, status from information_schema.events " +
+		"where event_schema = %a order by event_name"
+	qr, err := dbh.fetch(ctx, queryEvents, dbh.vtParams.DbName)
+	if err != nil {
+		return nil, err
+	}
+	events := make([]*Event, 0, len(qr.Rows))
+	for _, row := range qr.Rows {
+		schedule := "AT " + row[4].ToString()
+		if row[1].ToString() == "RECURRING" {
+			schedule = fmt.Sprintf("EVERY %s %s", row[2].ToString(), row[3].ToString())
+		}
+		events = append(events, &Event{
+			Name:     row[0].ToString(),
+			Schedule: schedule,
+			Status:   row[5].ToString(),
+		})
+	}
+	return events, nil
+}
+
+func (dbh *DBHelper) getViews(ctx context.Context) ([]*View, error) {
+	queryViews := "select table_name, view_definition, is_updatable from information_schema.views " +
+		"where table_schema = %a order by table_name"
+	qr, err := dbh.fetch(ctx, queryViews, dbh.vtParams.DbName)
+	if err != nil {
+		return nil, err
+	}
+	views := make([]*View, 0, len(qr.Rows))
+	for _, row := range qr.Rows {
+		views = append(views, &View{
+			Name:       row[0].ToString(),
+			Definition: row[1].ToString(),
+			Updatable:  row[2].ToString() == "YES",
+		})
+	}
+	return views, nil
+}
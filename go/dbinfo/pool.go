@@ -0,0 +1,148 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dbinfo
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"vitess.io/vitess/go/mysql"
+	"vitess.io/vitess/go/mysql/sqlerror"
+	"vitess.io/vitess/go/sqltypes"
+)
+
+const (
+	DefaultConnections  = 4
+	DefaultQueryTimeout = time.Minute
+)
+
+// connPool shares a few connections between the collectors running concurrently
+type connPool struct {
+	params  *mysql.ConnParams
+	timeout time.Duration
+	// idle are the open connections nobody uses
+	idle chan *mysql.Conn
+	// open has an element for every open connection, which limits how many can be opened
+	open chan struct{}
+}
+
+func newConnPool(params *mysql.ConnParams, size int, timeout time.Duration) *connPool {
+	if size <= 0 {
+		size = DefaultConnections
+	}
+	if timeout <= 0 {
+		timeout = DefaultQueryTimeout
+	}
+	return &connPool{
+		params:  params,
+		timeout: timeout,
+		idle:    make(chan *mysql.Conn, size),
+		open:    make(chan struct{}, size),
+	}
+}
+
+// get returns an idle connection, or opens a new one when the pool is not full
+func (p *connPool) get(ctx context.Context) (*mysql.Conn, error) {
+	select {
+	case conn := <-p.idle:
+		return conn, nil
+	default:
+	}
+
+	select {
+	case conn := <-p.idle:
+		return conn, nil
+	case p.open <- struct{}{}:
+		conn, err := mysql.Connect(ctx, p.params)
+		if err != nil {
+			<-p.open
+			return nil, err
+		}
+		return conn, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// put gives a connection back to the pool, a closed connection makes room for a new one
+func (p *connPool) put(conn *mysql.Conn) {
+	if conn.IsClosed() {
+		<-p.open
+		return
+	}
+	p.idle <- conn
+}
+
+func (p *connPool) close() {
+	for {
+		select {
+		case conn := <-p.idle:
+			conn.Close()
+			<-p.open
+		default:
+			return
+		}
+	}
+}
+
+// fetch runs a query on a connection of the pool. When the query does not finish within the timeout,
+// or the context is canceled, it is killed and its connection is closed. The time spent waiting for
+// a connection does not count towards the timeout.
+func (p *connPool) fetch(ctx context.Context, query string) (*sqltypes.Result, error) {
+	conn, err := p.get(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+
+	done := make(chan struct{})
+	killed := make(chan struct{})
+	go func() {
+		defer close(killed)
+		select {
+		case <-done:
+		case <-ctx.Done():
+			p.kill(conn)
+		}
+	}()
+	qr, err := conn.ExecuteFetch(query, -1, false)
+	close(done)
+	<-killed
+
+	if err != nil && ctx.Err() != nil {
+		err = fmt.Errorf("query interrupted after %v: %w", p.timeout, ctx.Err())
+	}
+	if sqlerror.IsConnErr(err) {
+		conn.Close()
+	}
+	p.put(conn)
+	return qr, err
+}
+
+// kill stops the query running on a connection on the server side, and closes the connection
+func (p *connPool) kill(conn *mysql.Conn) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if killer, err := mysql.Connect(ctx, p.params); err == nil {
+		_, _ = killer.ExecuteFetch(fmt.Sprintf("kill query %d", conn.ID()), 0, false)
+		killer.Close()
+	}
+	conn.Close()
+}
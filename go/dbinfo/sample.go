@@ -0,0 +1,96 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dbinfo
+
+import (
+	"context"
+	"fmt"
+
+	"vitess.io/vitess/go/sqlescape"
+)
+
+type (
+	// SampleConfig selects the columns whose values are sampled, and bounds the cost of sampling them
+	SampleConfig struct {
+		Columns []SampleColumn
+
+		// Rows is the number of rows read from the table for each column
+		Rows int
+
+		// TopN is the number of most frequent values kept for each column
+		TopN int
+	}
+
+	SampleColumn struct {
+		Table  string
+		Column string
+	}
+
+	// ColumnSample describes the values of a column in the first rows of its table.
+	// Those rows are not a random sample, but reading them is cheap even for large tables.
+	ColumnSample struct {
+		Rows      int          `json:"rows"`
+		Distinct  int          `json:"distinct"`
+		NullRatio float64      `json:"nullRatio"`
+		TopValues []ValueCount `json:"topValues,omitempty"`
+	}
+
+	ValueCount struct {
+		Value string `json:"value"`
+		Count int    `json:"count"`
+	}
+)
+
+// TopShare is the share of the sampled rows that have the most frequent value
+func (s *ColumnSample) TopShare() float64 {
+	if s.Rows == 0 || len(s.TopValues) == 0 {
+		return 0
+	}
+	return float64(s.TopValues[0].Count) / float64(s.Rows)
+}
+
+// getColumnSample samples a column of a table of the helper's schema
+func (dbh *DBHelper) getColumnSample(ctx context.Context, col SampleColumn, cfg SampleConfig) (*ColumnSample, error) {
+	column := sqlescape.EscapeID(col.Column)
+	table := sqlescape.EscapeID(dbh.vtParams.DbName) + "." + sqlescape.EscapeID(col.Table)
+	sample := fmt.Sprintf("(select %s from %s limit %d) as sample", column, table, cfg.Rows)
+
+	query := fmt.Sprintf("select count(*), count(%[1]s), count(distinct %[1]s) from %[2]s", column, sample)
+	qr, err := dbh.pool.fetch(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	rows, _ := qr.Rows[0][0].ToInt64()
+	nonNull, _ := qr.Rows[0][1].ToInt64()
+	distinct, _ := qr.Rows[0][2].ToInt64()
+	cs := &ColumnSample{Rows: int(rows), Distinct: int(distinct)}
+	if rows > 0 {
+		cs.NullRatio = float64(rows-nonNull) / float64(rows)
+	}
+
+	query = fmt.Sprintf("select %[1]s, count(*) as c from %[2]s where %[1]s is not null group by %[1]s order by c desc limit %[3]d",
+		column, sample, cfg.TopN)
+	qr, err = dbh.pool.fetch(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range qr.Rows {
+		count, _ := row[1].ToInt64()
+		cs.TopValues = append(cs.TopValues, ValueCount{Value: row[0].ToString(), Count: int(count)})
+	}
+	return cs, nil
+}
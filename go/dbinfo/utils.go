@@ -18,40 +18,54 @@ package dbinfo
 
 import (
 	"context"
-	"fmt"
 	"strings"
 
 	"vitess.io/vitess/go/mysql"
+	"vitess.io/vitess/go/sqltypes"
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	"vitess.io/vitess/go/vt/sqlparser"
 )
 
 type DBHelper struct {
 	vtParams *mysql.ConnParams
+	pool     *connPool
 }
 
 func NewDBHelper(vtParams *mysql.ConnParams) *DBHelper {
-	return &DBHelper{vtParams: vtParams}
+	return &DBHelper{vtParams: vtParams, pool: newConnPool(vtParams, DefaultConnections, DefaultQueryTimeout)}
 }
 
-func (dbh *DBHelper) GetConnection() (*mysql.Conn, func(), error) {
-	vtConn, err := mysql.Connect(context.Background(), dbh.vtParams)
-	if err != nil {
-		return nil, nil, err
-	}
-	return vtConn, func() { vtConn.Close() }, nil
+// forSchema is a helper for another schema, using the same connections
+func (dbh *DBHelper) forSchema(schema string) *DBHelper {
+	params := *dbh.vtParams
+	params.DbName = schema
+	return &DBHelper{vtParams: &params, pool: dbh.pool}
 }
 
-type tableSizes map[string]int
-
-func (dbh *DBHelper) getTableSizes() (tableSizes, error) {
-	dbName := dbh.vtParams.DbName
-	vtConn, cancel, err := dbh.GetConnection()
+// fetch runs a query with its %a placeholders bound to the given values, escaped as string literals
+func (dbh *DBHelper) fetch(ctx context.Context, query string, values ...string) (*sqltypes.Result, error) {
+	binds := make([]*querypb.BindVariable, 0, len(values))
+	for _, value := range values {
+		binds = append(binds, sqltypes.StringBindVariable(value))
+	}
+	bound, err := sqlparser.ParseAndBind(query, binds...)
 	if err != nil {
 		return nil, err
 	}
-	defer cancel()
-	queryTableSizes := "select table_name, table_rows from information_schema.tables where table_schema = '%s' and table_type = 'BASE TABLE'"
-	query := fmt.Sprintf(queryTableSizes, dbName)
-	qr, err := vtConn.ExecuteFetch(query, -1, false)
+	return dbh.pool.fetch(ctx, bound)
+}
+
+type tableSize struct {
+	rows int
+	TableSize
+}
+
+type tableSizes map[string]*tableSize
+
+func (dbh *DBHelper) getTableSizes(ctx context.Context) (tableSizes, error) {
+	queryTableSizes := "select table_name, table_rows, engine, data_length, index_length, avg_row_length, auto_increment " +
+		"from information_schema.tables where table_schema = %a and table_type = 'BASE TABLE'"
+	qr, err := dbh.fetch(ctx, queryTableSizes, dbh.vtParams.DbName)
 	if err != nil {
 		return nil, err
 	}
@@ -59,95 +73,98 @@ func (dbh *DBHelper) getTableSizes() (tableSizes, error) {
 	for _, row := range qr.Rows {
 		tableName := row[0].ToString()
 		tableRows, _ := row[1].ToInt64()
-		ts[tableName] = int(tableRows)
+		size := &tableSize{rows: int(tableRows)}
+		size.Engine = row[2].ToString()
+		size.DataLength, _ = row[3].ToInt64()
+		size.IndexLength, _ = row[4].ToInt64()
+		size.AvgRowLength, _ = row[5].ToInt64()
+		// auto_increment is NULL for tables without an auto-increment column
+		size.AutoIncrement, _ = row[6].ToUint64()
+		ts[tableName] = size
 	}
 	return ts, nil
 }
 
 type tableColumns map[string][]*TableColumn
 
-func (dbh *DBHelper) getColumnInfo() (tableColumns, error) {
-	vtConn, cancel, err := dbh.GetConnection()
-	if err != nil {
-		return nil, err
-	}
-	defer cancel()
-	queryColumnInfo := "select table_name, column_name, data_type, column_key, is_nullable, extra from information_schema.columns where table_schema = '%s'"
-	query := fmt.Sprintf(queryColumnInfo, dbh.vtParams.DbName)
-	qr, err := vtConn.ExecuteFetch(query, -1, false)
+func (dbh *DBHelper) getColumnInfo(ctx context.Context) (tableColumns, error) {
+	queryColumnInfo := "select table_name, column_name, data_type, column_type, column_key, is_nullable, extra, " +
+		"character_set_name, collation_name, column_default, generation_expression " +
+		"from information_schema.columns where table_schema = %a order by table_name, ordinal_position"
+	qr, err := dbh.fetch(ctx, queryColumnInfo, dbh.vtParams.DbName)
 	if err != nil {
 		return nil, err
 	}
 	tc := make(tableColumns)
 	for _, row := range qr.Rows {
 		tableName := row[0].ToString()
-		columnName := row[1].ToString()
-		dataType := strings.ToLower(row[2].ToString())
-		columnKey := strings.ToLower(row[3].ToString())
-		isNullable := row[4].ToString()
-		extra := strings.ToLower(row[5].ToString())
 		col := &TableColumn{
-			Name:       columnName,
-			Type:       dataType,
-			KeyType:    columnKey,
-			IsNullable: strings.EqualFold(isNullable, "YES"),
-			Extra:      extra,
+			Name:                 row[1].ToString(),
+			Type:                 strings.ToLower(row[2].ToString()),
+			ColumnType:           strings.ToLower(row[3].ToString()),
+			KeyType:              strings.ToLower(row[4].ToString()),
+			IsNullable:           strings.EqualFold(row[5].ToString(), "YES"),
+			Extra:                strings.ToLower(row[6].ToString()),
+			Charset:              row[7].ToString(),
+			Collation:            row[8].ToString(),
+			GenerationExpression: row[10].ToString(),
+		}
+		if !row[9].IsNull() {
+			def := row[9].ToString()
+			col.Default = &def
 		}
 		tc[tableName] = append(tc[tableName], col)
 	}
 	return tc, nil
 }
 
-func (dbh *DBHelper) getGlobalVariables() (map[string]string, error) {
-	// Currently only use simple regex to match the variable names
-	// If the variable name contains ".*" then it is treated as a regex, else exact match
-	globalVariablesToFetch := []string{
-		"binlog_format",
-		"binlog_row_image",
-		"log_bin",
-		"gtid_mode",
-	}
-
-	vtConn, cancel, err := dbh.GetConnection()
+// getGlobalVariables fetches the global variables matching the given names or regular expressions
+func (dbh *DBHelper) getGlobalVariables(ctx context.Context, patterns []string) (map[string]string, error) {
+	matcher, err := newVariableMatcher(patterns)
 	if err != nil {
 		return nil, err
 	}
-	defer cancel()
+
 	queryGlobalVars := "show global variables"
-	qr, err := vtConn.ExecuteFetch(queryGlobalVars, -1, false)
+	qr, err := dbh.fetch(ctx, queryGlobalVars)
 	if err != nil {
 		return nil, err
 	}
 	gv := make(map[string]string)
 	for _, row := range qr.Rows {
 		variable := row[0].ToString()
-		value := row[1].ToString()
-		for _, gvName := range globalVariablesToFetch {
-			if variable == gvName {
-				gv[variable] = value
-			}
+		if matcher.match(variable) {
+			gv[variable] = row[1].ToString()
 		}
 	}
 	return gv, nil
 }
 
+// getSchemas lists the schemas of the server, without the system schemas
+func (dbh *DBHelper) getSchemas(ctx context.Context) ([]string, error) {
+	querySchemas := "select schema_name from information_schema.schemata " +
+		"where schema_name not in ('mysql', 'information_schema', 'performance_schema', 'sys') order by schema_name"
+	qr, err := dbh.fetch(ctx, querySchemas)
+	if err != nil {
+		return nil, err
+	}
+	schemas := make([]string, 0, len(qr.Rows))
+	for _, row := range qr.Rows {
+		schemas = append(schemas, row[0].ToString())
+	}
+	return schemas, nil
+}
+
 type primaryKey struct {
 	tableName string
 	columns   []string
 }
 type primaryKeys map[string]*primaryKey
 
-func (dbh *DBHelper) getPrimaryKeys() (primaryKeys, error) {
-	vtConn, cancel, err := dbh.GetConnection()
-	if err != nil {
-		return nil, err
-	}
-	defer cancel()
-
+func (dbh *DBHelper) getPrimaryKeys(ctx context.Context) (primaryKeys, error) {
 	pks := make(primaryKeys)
-	queryPrimaryKeys := "select table_name, column_name from information_schema.key_column_usage where constraint_name = 'PRIMARY' and table_schema = '%s' order by table_name"
-	query := fmt.Sprintf(queryPrimaryKeys, dbh.vtParams.DbName)
-	qr, err := vtConn.ExecuteFetch(query, -1, false)
+	queryPrimaryKeys := "select table_name, column_name from information_schema.key_column_usage where constraint_name = 'PRIMARY' and table_schema = %a order by table_name"
+	qr, err := dbh.fetch(ctx, queryPrimaryKeys, dbh.vtParams.DbName)
 	if err != nil {
 		return nil, err
 	}
@@ -169,17 +186,11 @@ type tableIndex struct {
 	indexes   map[string]*Index
 }
 
-func (dbh *DBHelper) getIndexes() (map[string]*tableIndex, error) {
-	vtConn, cancel, err := dbh.GetConnection()
-	if err != nil {
-		return nil, err
-	}
-	defer cancel()
-
+func (dbh *DBHelper) getIndexes(ctx context.Context) (map[string]*tableIndex, error) {
 	idxs := make(map[string]*tableIndex)
-	queryIndexes := "select table_name, index_name, column_name, non_unique from information_schema.statistics where table_schema = '%s' order by table_name, index_name"
-	query := fmt.Sprintf(queryIndexes, dbh.vtParams.DbName)
-	qr, err := vtConn.ExecuteFetch(query, -1, false)
+	queryIndexes := "select table_name, index_name, column_name, non_unique, cardinality from information_schema.statistics " +
+		"where table_schema = %a order by table_name, index_name, seq_in_index"
+	qr, err := dbh.fetch(ctx, queryIndexes, dbh.vtParams.DbName)
 	if err != nil {
 		return nil, err
 	}
@@ -203,21 +214,17 @@ func (dbh *DBHelper) getIndexes() (map[string]*tableIndex, error) {
 			tidx.indexes[idxName] = idx
 		}
 		idx.Columns = append(idx.Columns, columnName)
+		cardinality, _ := row[4].ToInt64()
+		idx.Cardinality = append(idx.Cardinality, cardinality)
 	}
 	return idxs, nil
 }
 
-func (dbh *DBHelper) getForeignKeys() (map[string][]*ForeignKey, error) {
-	vtConn, cancel, err := dbh.GetConnection()
-	if err != nil {
-		return nil, err
-	}
-	defer cancel()
-
+func (dbh *DBHelper) getForeignKeys(ctx context.Context) (map[string][]*ForeignKey, error) {
 	fks := make(map[string][]*ForeignKey)
-	queryForeignKeys := "select table_name, column_name, constraint_name, referenced_table_name, referenced_column_name from information_schema.key_column_usage where table_schema = '%s' and referenced_table_name is not null"
-	query := fmt.Sprintf(queryForeignKeys, dbh.vtParams.DbName)
-	qr, err := vtConn.ExecuteFetch(query, -1, false)
+	queryForeignKeys := "select table_name, column_name, constraint_name, referenced_table_name, referenced_column_name, referenced_table_schema " +
+		"from information_schema.key_column_usage where table_schema = %a and referenced_table_name is not null"
+	qr, err := dbh.fetch(ctx, queryForeignKeys, dbh.vtParams.DbName)
 	if err != nil {
 		return nil, err
 	}
@@ -229,6 +236,9 @@ func (dbh *DBHelper) getForeignKeys() (map[string][]*ForeignKey, error) {
 			ReferencedTableName:  row[3].ToString(),
 			ReferencedColumnName: row[4].ToString(),
 		}
+		if refSchema := row[5].ToString(); refSchema != dbh.vtParams.DbName {
+			fk.ReferencedSchemaName = refSchema
+		}
 		fks[tableName] = append(fks[tableName], fk)
 	}
 	return fks, nil
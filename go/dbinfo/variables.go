@@ -0,0 +1,87 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dbinfo
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// DefaultGlobalVariables are the global variables that matter when moving to Vitess,
+// they are always collected on top of the configured ones
+func DefaultGlobalVariables() []string {
+	return []string{
+		"version",
+		"version_comment",
+		"binlog_format",
+		"binlog_row_image",
+		"log_bin",
+		"gtid_mode",
+		"enforce_gtid_consistency",
+		"sql_mode",
+		"character_set_server",
+		"collation_server",
+		"lower_case_table_names",
+		"transaction_isolation",
+		"max_connections",
+		"default_storage_engine",
+		"explicit_defaults_for_timestamp",
+		"time_zone",
+		"innodb_buffer_pool_size",
+		"innodb_autoinc_lock_mode",
+		"innodb_flush_log_at_trx_commit",
+		"innodb_lock_wait_timeout",
+		"innodb_file_per_table",
+	}
+}
+
+// variableMatcher matches variable names against names and regular expressions.
+// A pattern with regular expression metacharacters, like innodb_.*, has to match the whole name,
+// any other pattern is compared to the name ignoring case
+type variableMatcher struct {
+	names   map[string]bool
+	regexps []*regexp.Regexp
+}
+
+func newVariableMatcher(patterns []string) (*variableMatcher, error) {
+	m := &variableMatcher{names: make(map[string]bool)}
+	for _, pattern := range patterns {
+		if regexp.QuoteMeta(pattern) == pattern {
+			m.names[strings.ToLower(pattern)] = true
+			continue
+		}
+		re, err := regexp.Compile("(?i)^(?:" + pattern + ")$")
+		if err != nil {
+			return nil, fmt.Errorf("invalid global variable pattern %q: %w", pattern, err)
+		}
+		m.regexps = append(m.regexps, re)
+	}
+	return m, nil
+}
+
+func (m *variableMatcher) match(name string) bool {
+	if m.names[strings.ToLower(name)] {
+		return true
+	}
+	for _, re := range m.regexps {
+		if re.MatchString(name) {
+			return true
+		}
+	}
+	return false
+}
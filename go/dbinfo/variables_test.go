@@ -0,0 +1,40 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dbinfo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVariableMatcher(t *testing.T) {
+	m, err := newVariableMatcher([]string{"sql_mode", "innodb_.*", "binlog_(format|row_image)"})
+	require.NoError(t, err)
+
+	assert.True(t, m.match("sql_mode"))
+	assert.True(t, m.match("SQL_MODE"))
+	assert.True(t, m.match("innodb_buffer_pool_size"))
+	assert.True(t, m.match("binlog_row_image"))
+	assert.False(t, m.match("binlog_cache_size"))
+	assert.False(t, m.match("sql_mode_extra"))
+	assert.False(t, m.match("my_innodb_setting"))
+
+	_, err = newVariableMatcher([]string{"innodb_("})
+	require.ErrorContains(t, err, `invalid global variable pattern "innodb_("`)
+}
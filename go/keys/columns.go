@@ -0,0 +1,71 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package keys
+
+import (
+	"sort"
+
+	"vitess.io/vitess/go/vt/sqlparser"
+)
+
+// KeyColumn is a column used in equality filters or join predicates, the way sharding keys are used
+type KeyColumn struct {
+	Table  string
+	Column string
+
+	// Usage is the number of executions of the queries using the column
+	Usage int
+}
+
+// KeyColumns lists the columns used in equality filters and join predicates, most used first
+func (o Output) KeyColumns() []KeyColumn {
+	usage := map[KeyColumn]int{}
+	add := func(table, column string, count int) {
+		usage[KeyColumn{Table: table, Column: column}] += count
+	}
+	for _, q := range o.Queries {
+		w := max(q.UsageCount, 1)
+		for _, fc := range q.FilterColumns {
+			if fc.Uses == sqlparser.EqualOp || fc.Uses == sqlparser.InOp {
+				add(fc.Column.Table, fc.Column.Name, w)
+			}
+		}
+		for _, jp := range q.JoinPredicates {
+			if jp.Uses == sqlparser.EqualOp {
+				add(jp.LHS.Table, jp.LHS.Name, w)
+				add(jp.RHS.Table, jp.RHS.Name, w)
+			}
+		}
+	}
+
+	columns := make([]KeyColumn, 0, len(usage))
+	for col, count := range usage {
+		col.Usage = count
+		columns = append(columns, col)
+	}
+	sort.Slice(columns, func(i, j int) bool {
+		a, b := columns[i], columns[j]
+		if a.Usage != b.Usage {
+			return a.Usage > b.Usage
+		}
+		if a.Table != b.Table {
+			return a.Table < b.Table
+		}
+		return a.Column < b.Column
+	})
+	return columns
+}
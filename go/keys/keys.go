@@ -21,6 +21,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"maps"
 	"sort"
 
 	querypb "vitess.io/vitess/go/vt/proto/query"
@@ -36,6 +37,18 @@ type (
 	Config struct {
 		FileName string
 		Loader   data.Loader
+
+		// Parameterize replaces the literals of the queries with bind variables,
+		// so queries that only differ in their values share a query structure
+		Parameterize bool
+
+		// Samples is the number of executions per query structure whose bind variables are kept
+		Samples int
+
+		Planner data.PlannerOptions
+
+		// DBInfoFile is a vt dbinfo output whose column definitions are used to plan the queries
+		DBInfoFile string
 	}
 	// Output represents the output generated by 'vt keys'
 	Output struct {
@@ -46,6 +59,9 @@ type (
 	queryList struct {
 		queries map[string]*QueryAnalysisResult
 		failed  map[string]*QueryFailedResult
+
+		parameterize bool
+		samples      int
 	}
 	// QueryAnalysisResult represents the result of analyzing a query in a query log. It contains the query structure, the number of
 	// times the query was used, the line numbers where the query was used, the table name, grouping columns, join columns,
@@ -64,6 +80,10 @@ type (
 		RowsSent        int                       `json:"rowsSent,omitempty"`
 		RowsExamined    int                       `json:"rowsExamined,omitempty"`
 		Timestamp       int64                     `json:"timestamp,omitempty"`
+
+		// Samples are the bind variables of the first executions of the query, when Config.Samples is set.
+		// They are only kept in memory, so the values seen in the log do not end up in the output.
+		Samples []map[string]*querypb.BindVariable `json:"-"`
 	}
 	QueryFailedResult struct {
 		Query       string `json:"query"`
@@ -73,12 +93,55 @@ type (
 )
 
 func Run(out io.Writer, cfg Config) error {
+	si, err := newSchemaInfo(cfg)
+	if err != nil {
+		return err
+	}
+
+	res, closeErr := analyze(cfg, si)
+	jsonWriteErr := writeJSONTo(out, res)
+
+	return errors.Join(closeErr, jsonWriteErr)
+}
+
+// Analyze runs the keys analysis on the queries of the log file without writing the result,
+// so other commands can start from a query log instead of a keys file
+func Analyze(cfg Config) (Output, error) {
+	si, err := newSchemaInfo(cfg)
+	if err != nil {
+		return Output{}, err
+	}
+	return analyze(cfg, si)
+}
+
+func newSchemaInfo(cfg Config) (*SchemaInfo, error) {
+	env, err := cfg.Planner.Environment()
+	if err != nil {
+		return nil, err
+	}
+	fkMode, err := cfg.Planner.ForeignKeys()
+	if err != nil {
+		return nil, err
+	}
 	si := &SchemaInfo{
 		Tables: make(map[string]Columns),
+		Env:    env,
+		FKMode: fkMode,
 	}
+	if cfg.DBInfoFile != "" {
+		if err := si.addDBInfoColumns(cfg.DBInfoFile); err != nil {
+			return nil, err
+		}
+	}
+	return si, nil
+}
+
+func analyze(cfg Config, si *SchemaInfo) (Output, error) {
 	ql := &queryList{
-		queries: make(map[string]*QueryAnalysisResult),
-		failed:  make(map[string]*QueryFailedResult),
+		queries:      make(map[string]*QueryAnalysisResult),
+		failed:       make(map[string]*QueryFailedResult),
+		parameterize: cfg.Parameterize,
+		samples:      cfg.Samples,
 	}
 
 	loader := cfg.Loader.Load(cfg.FileName)
@@ -88,14 +151,11 @@ func Run(out io.Writer, cfg Config) error {
 		return nil
 	})
 
-	closeErr := loader.Close()
-	jsonWriteErr := ql.writeJSONTo(out)
-
-	return errors.Join(closeErr, jsonWriteErr)
+	return ql.output(), loader.Close()
 }
 
 func process(q data.Query, si *SchemaInfo, ql *queryList) {
-	ast, bv, err := sqlparser.NewTestParser().Parse2(q.Query)
+	ast, bv, err := si.Environment().Parser().Parse2(q.Query)
 	if err != nil {
 		ql.addFailedQuery(q, err)
 		return
@@ -118,8 +178,13 @@ func (ql *queryList) processQuery(si *SchemaInfo, ast sqlparser.Statement, q dat
 	}()
 
 	mapBv := make(map[string]*querypb.BindVariable)
-	reservedVars := sqlparser.NewReservedVars("", bv)
-	_, err := sqlparser.Normalize(ast, reservedVars, mapBv, false, si.KsName, 1000, "", map[string]string{}, nil, nil)
+	prefix := ""
+	if ql.parameterize {
+		// bind variables named like vtgate names them, as purely numeric names cannot be used for list arguments
+		prefix = "vtg"
+	}
+	reservedVars := sqlparser.NewReservedVars(prefix, bv)
+	_, err := sqlparser.Normalize(ast, reservedVars, mapBv, ql.parameterize, si.KsName, 1000, "", map[string]string{}, nil, nil)
 	if err != nil {
 		ql.addFailedQuery(q, err)
 		return
@@ -148,6 +213,7 @@ func (ql *queryList) processQuery(si *SchemaInfo, ast sqlparser.Statement, q dat
 		r.LockTime += q.LockTime
 		r.RowsSent += q.RowsSent
 		r.RowsExamined += q.RowsExamined
+		r.addSample(ql.samples, q.BindVars, mapBv)
 		return
 	}
 
@@ -161,7 +227,7 @@ func (ql *queryList) processQuery(si *SchemaInfo, ast sqlparser.Statement, q dat
 	}
 
 	result := operators.GetVExplainKeys(ctx, ast)
-	ql.queries[structure] = &QueryAnalysisResult{
+	r = &QueryAnalysisResult{
 		QueryStructure:  structure,
 		StatementType:   result.StatementType,
 		UsageCount:      usageCount,
@@ -176,6 +242,19 @@ func (ql *queryList) processQuery(si *SchemaInfo, ast sqlparser.Statement, q dat
 		RowsExamined:    q.RowsExamined,
 		Timestamp:       q.Timestamp,
 	}
+	r.addSample(ql.samples, q.BindVars, mapBv)
+	ql.queries[structure] = r
+}
+
+// addSample keeps the bind variables of one execution, both the ones from the log and the ones extracted from literals
+func (r *QueryAnalysisResult) addSample(limit int, logged, extracted map[string]*querypb.BindVariable) {
+	if len(r.Samples) >= limit || len(logged)+len(extracted) == 0 {
+		return
+	}
+	sample := make(map[string]*querypb.BindVariable, len(logged)+len(extracted))
+	maps.Copy(sample, logged)
+	maps.Copy(sample, extracted)
+	r.Samples = append(r.Samples, sample)
 }
 
 func (ql *queryList) addFailedQuery(q data.Query, err error) {
@@ -191,8 +270,8 @@ func (ql *queryList) addFailedQuery(q data.Query, err error) {
 	}
 }
 
-// writeJsonTo writes the query list, sorted by the first line number of the query, to the given writer.
-func (ql *queryList) writeJSONTo(w io.Writer) error {
+// output returns the query list, sorted by the first line number of the query
+func (ql *queryList) output() Output {
 	values := make([]QueryAnalysisResult, 0, len(ql.queries))
 	for _, result := range ql.queries {
 		values = append(values, *result)
@@ -209,12 +288,14 @@ func (ql *queryList) writeJSONTo(w io.Writer) error {
 		return failedQueries[i].LineNumbers[0] < failedQueries[j].LineNumbers[0]
 	})
 
-	res := Output{
+	return Output{
 		FileType: "keys",
 		Queries:  values,
 		Failed:   failedQueries,
 	}
+}
 
+func writeJSONTo(w io.Writer, res Output) error {
 	jsonData, err := json.MarshalIndent(res, "  ", "  ")
 	if err != nil {
 		return err
@@ -96,3 +96,55 @@ func TestKeysNonAuthoritativeTable(t *testing.T) {
 		require.NotEmpty(t, result.FilterColumns)
 	}
 }
+
+func TestKeysSamples(t *testing.T) {
+	res, err := Analyze(Config{
+		FileName:     "../testdata/query-logs/customers-fanout.log",
+		Loader:       data.SlowQueryLogLoader{},
+		Parameterize: true,
+		Samples:      2,
+	})
+	require.NoError(t, err)
+	require.NotEmpty(t, res.Queries)
+
+	first := res.Queries[0]
+	assert.Equal(t, "SELECT `customer_name` FROM `customers` WHERE `customer_id` = :customer_id /* INT64 */ LIMIT :vtg1 /* INT64 */", first.QueryStructure)
+	assert.Equal(t, 3, first.UsageCount)
+	require.Len(t, first.Samples, 2)
+	assert.Equal(t, "1", string(first.Samples[0]["customer_id"].Value))
+	assert.Equal(t, "2", string(first.Samples[1]["customer_id"].Value))
+}
+
+func TestKeysDBInfoColumns(t *testing.T) {
+	q := data.Query{
+		Query: "select c_name from customer, orders where c_custkey = o_custkey and o_orderstatus = 'F'",
+		Type:  data.SQLQuery,
+	}
+	si, err := newSchemaInfo(Config{DBInfoFile: "../testdata/dbInfo-output/tpch-dbinfo.json"})
+	require.NoError(t, err)
+	ql := &queryList{
+		queries: make(map[string]*QueryAnalysisResult),
+		failed:  make(map[string]*QueryFailedResult),
+	}
+	process(q, si, ql)
+
+	require.Len(t, ql.queries, 1)
+	for _, result := range ql.queries {
+		require.Len(t, result.JoinPredicates, 1)
+		assert.Equal(t, "customer.c_custkey = orders.o_custkey", result.JoinPredicates[0].String())
+		require.Len(t, result.FilterColumns, 1)
+		assert.Equal(t, "orders.o_orderstatus =", result.FilterColumns[0].String())
+	}
+}
+
+func TestKeyColumns(t *testing.T) {
+	ko, err := ReadKeysFile("../testdata/keys-output/keys-log.json")
+	require.NoError(t, err)
+
+	columns := ko.KeyColumns()
+	require.NotEmpty(t, columns)
+	assert.Equal(t, KeyColumn{Table: "lineitem", Column: "l_orderkey", Usage: 12}, columns[0])
+	for i := 1; i < len(columns); i++ {
+		assert.GreaterOrEqual(t, columns[i-1].Usage, columns[i].Usage)
+	}
+}
@@ -25,6 +25,8 @@ import (
 	"vitess.io/vitess/go/vt/vtenv"
 	"vitess.io/vitess/go/vt/vtgate/semantics"
 	"vitess.io/vitess/go/vt/vtgate/vindexes"
+
+	"github.com/vitessio/vt/go/dbinfo"
 )
 
 var _ semantics.SchemaInformation = (*SchemaInfo)(nil)
@@ -37,6 +39,10 @@ type (
 	SchemaInfo struct {
 		KsName string
 		Tables map[string]Columns
+
+		// Env and FKMode default to the test environment and unmanaged foreign keys when they are not set
+		Env    *vtenv.Environment
+		FKMode vschemapb.Keyspace_ForeignKeyMode
 	}
 
 	Columns []vindexes.Column
@@ -53,6 +59,36 @@ func (s *SchemaInfo) handleCreateTable(create *sqlparser.CreateTable) {
 	s.Tables[create.Table.Name.String()] = columns
 }
 
+// addDBInfoColumns makes the columns of the tables in a dbinfo file known, with their types and nullability.
+// CREATE TABLE statements in the query log still replace them. A table name that several schemas of the file
+// have is only known qualified by its schema, since the unqualified name could be any of them.
+func (s *SchemaInfo) addDBInfoColumns(fileName string) error {
+	info, err := dbinfo.Load(fileName)
+	if err != nil {
+		return err
+	}
+	schemas := map[string]int{}
+	for _, table := range info.Tables {
+		schemas[table.Name]++
+	}
+	for _, table := range info.Tables {
+		if len(table.Columns) == 0 {
+			continue
+		}
+		columns := make(Columns, 0, len(table.Columns))
+		for _, col := range table.Columns {
+			columns = append(columns, col.VindexColumn())
+		}
+		if table.Schema != "" {
+			s.Tables[table.QualifiedName()] = columns
+		}
+		if schemas[table.Name] == 1 {
+			s.Tables[table.Name] = columns
+		}
+	}
+	return nil
+}
+
 func (s *SchemaInfo) FindTableOrVindex(tablename sqlparser.TableName) (*vindexes.BaseTable, vindexes.Vindex, string, topodata.TabletType, key.ShardDestination, error) {
 	var tbl *vindexes.BaseTable
 	ks := tablename.Qualifier.String()
@@ -73,6 +109,19 @@ func (s *SchemaInfo) FindTableOrVindex(tablename sqlparser.TableName) (*vindexes
 		}
 	}
 
+	if tbl == nil && tablename.Qualifier.NotEmpty() {
+		// a table qualified by the schema it has in a dbinfo file
+		columns, found := s.Tables[tablename.Qualifier.String()+"."+tablename.Name.String()]
+		if found {
+			tbl = &vindexes.BaseTable{
+				Name:                    tablename.Name,
+				Keyspace:                &vindexes.Keyspace{Name: ks, Sharded: true},
+				Columns:                 columns,
+				ColumnListAuthoritative: true,
+			}
+		}
+	}
+
 	if tbl == nil {
 		// This is a table from another keyspace, or we couldn't find it in our keyspace
 		tbl = &vindexes.BaseTable{
@@ -90,11 +139,17 @@ func (s *SchemaInfo) ConnCollation() collations.ID {
 }
 
 func (s *SchemaInfo) Environment() *vtenv.Environment {
-	return vtenv.NewTestEnv()
+	if s.Env == nil {
+		s.Env = vtenv.NewTestEnv()
+	}
+	return s.Env
 }
 
 func (s *SchemaInfo) ForeignKeyMode(string) (vschemapb.Keyspace_ForeignKeyMode, error) {
-	return vschemapb.Keyspace_unmanaged, nil
+	if s.FKMode == vschemapb.Keyspace_unspecified {
+		return vschemapb.Keyspace_unmanaged, nil
+	}
+	return s.FKMode, nil
 }
 
 func (s *SchemaInfo) GetForeignKeyChecksState() *bool {
@@ -17,6 +17,8 @@ limitations under the License.
 package keys
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -60,3 +62,32 @@ func TestSchemaInfo(t *testing.T) {
 	})
 	utils.MustMatch(t, []string{"INT32", "VARCHAR", "VARCHAR", "CHAR", "CHAR", "DECIMAL", "DECIMAL"}, colTypes)
 }
+
+func TestSchemaInfoDBInfoSchemas(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), "dbinfo.json")
+	err := os.WriteFile(fileName, []byte(`{
+  "fileType": "dbinfo",
+  "tables": [
+    {"name": "customers", "schema": "billing", "columns": [{"name": "id", "type": "bigint"}]},
+    {"name": "customers", "schema": "shop", "columns": [{"name": "id", "type": "bigint"}, {"name": "email", "type": "varchar"}]},
+    {"name": "orders", "schema": "shop", "columns": [{"name": "id", "type": "bigint"}]}
+  ]
+}`), 0o644)
+	require.NoError(t, err)
+
+	si := &SchemaInfo{Tables: make(map[string]Columns)}
+	require.NoError(t, si.addDBInfoColumns(fileName))
+
+	find := func(qualifier, name string) *vindexes.BaseTable {
+		table, _, _, _, _, err := si.FindTableOrVindex(sqlparser.NewTableNameWithQualifier(name, qualifier))
+		require.NoError(t, err)
+		return table
+	}
+
+	// only shop has an orders table
+	require.True(t, find("", "orders").ColumnListAuthoritative)
+	// both schemas have a customers table, an unqualified name could be either of them
+	require.False(t, find("", "customers").ColumnListAuthoritative)
+	require.Len(t, find("billing", "customers").Columns, 1)
+	require.Len(t, find("shop", "customers").Columns, 2)
+}
@@ -0,0 +1,222 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package planalyze
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	"github.com/vitessio/vt/go/keys"
+)
+
+type (
+	// CompareOutput is the result of planning the same queries with two vschemas
+	CompareOutput struct {
+		FileType       string `json:"fileType"`
+		VSchema        string `json:"vschema"`
+		CompareVSchema string `json:"compareVSchema"`
+
+		// Before and After are the totals per complexity with the first and the second vschema
+		Before ComplexityTotals `json:"before"`
+		After  ComplexityTotals `json:"after"`
+
+		// BeforeWrites and AfterWrites show the cost of maintaining vindexes and sequences with each vschema
+		BeforeWrites *WriteAmplification `json:"beforeWrites,omitempty"`
+		AfterWrites  *WriteAmplification `json:"afterWrites,omitempty"`
+
+		// Transitions are the totals of the queries that changed complexity, grouped by the change
+		Transitions []Transition `json:"transitions"`
+		// Changed are the queries that changed complexity, the most used first
+		Changed []QueryComparison `json:"changed"`
+		// NewlyUnplannable are the queries that can only be planned with the first vschema
+		NewlyUnplannable []QueryComparison `json:"newlyUnplannable"`
+	}
+
+	ComplexityTotals struct {
+		PassThrough  Weight `json:"passThrough"`
+		SimpleRouted Weight `json:"simpleRouted"`
+		Scatter      Weight `json:"scatter"`
+		Complex      Weight `json:"complex"`
+		Unplannable  Weight `json:"unplannable"`
+	}
+
+	// Weight is the number of query structures and the part of the workload they represent
+	Weight struct {
+		Queries    int     `json:"queries"`
+		Executions int     `json:"executions"`
+		QueryTime  float64 `json:"queryTime"`
+	}
+
+	Transition struct {
+		From PlanComplexity `json:"from"`
+		To   PlanComplexity `json:"to"`
+		Weight
+	}
+
+	QueryComparison struct {
+		QueryStructure string         `json:"queryStructure"`
+		UsageCount     int            `json:"usageCount"`
+		QueryTime      float64        `json:"queryTime"`
+		Before         PlanComplexity `json:"before"`
+		After          PlanComplexity `json:"after"`
+		// Error is the planning error with the second vschema, if the query could not be planned
+		Error string `json:"error,omitempty"`
+	}
+)
+
+// Get returns the totals of the given complexity
+func (ct *ComplexityTotals) Get(c PlanComplexity) *Weight {
+	switch c {
+	case PassThrough:
+		return &ct.PassThrough
+	case SimpleRouted:
+		return &ct.SimpleRouted
+	case Scatter:
+		return &ct.Scatter
+	case Complex:
+		return &ct.Complex
+	default:
+		return &ct.Unplannable
+	}
+}
+
+// Totals sums up the queries of each complexity
+func (p *Planalyze) Totals() ComplexityTotals {
+	var totals ComplexityTotals
+	for complexity, queries := range p.Queries {
+		for _, q := range queries {
+			totals.Get(PlanComplexity(complexity)).add(q)
+		}
+	}
+	return totals
+}
+
+func (w *Weight) add(q AnalyzedQuery) {
+	w.Queries++
+	w.Executions += q.UsageCount
+	w.QueryTime += q.QueryTime
+}
+
+func runCompare(out io.Writer, cfg Config, pe *PlanEnv, queries []keys.QueryAnalysisResult, before *Planalyze) error {
+	vschemaFile, vtexplainFile := cfg.CompareVSchemaFile, ""
+	if cfg.VtExplainVschemaFile != "" {
+		vschemaFile, vtexplainFile = "", cfg.CompareVSchemaFile
+	}
+	vw, err := loadVSchema(vschemaFile, vtexplainFile, pe)
+	if err != nil {
+		return fmt.Errorf("could not load the vschema to compare with: %w", err)
+	}
+
+	after, err := analyze(vw, queries, 0)
+	if err != nil {
+		return err
+	}
+
+	res := Compare(before, after)
+	res.VSchema = cfg.VSchemaFile + cfg.VtExplainVschemaFile
+	res.CompareVSchema = cfg.CompareVSchemaFile
+	return writeJSON(out, res)
+}
+
+// Compare matches the queries of two analyses by their structure and reports the ones that changed complexity
+func Compare(before, after *Planalyze) CompareOutput {
+	res := CompareOutput{
+		FileType: "planalyzeCompare",
+		Before:   before.Totals(),
+		After:    after.Totals(),
+
+		BeforeWrites: before.WriteAmplification(),
+		AfterWrites:  after.WriteAmplification(),
+	}
+
+	beforeByQuery := map[string]AnalyzedQuery{}
+	for _, queries := range before.Queries {
+		for _, q := range queries {
+			beforeByQuery[q.QueryStructure] = q
+		}
+	}
+
+	transitions := map[[2]PlanComplexity]*Weight{}
+	for _, queries := range after.Queries {
+		for _, q := range queries {
+			old, found := beforeByQuery[q.QueryStructure]
+			if !found || old.Complexity == q.Complexity {
+				continue
+			}
+
+			key := [2]PlanComplexity{old.Complexity, q.Complexity}
+			if transitions[key] == nil {
+				transitions[key] = &Weight{}
+			}
+			transitions[key].add(q)
+
+			comparison := QueryComparison{
+				QueryStructure: q.QueryStructure,
+				UsageCount:     q.UsageCount,
+				QueryTime:      q.QueryTime,
+				Before:         old.Complexity,
+				After:          q.Complexity,
+			}
+			if q.Complexity == Unplannable {
+				_ = json.Unmarshal(q.PlanOutput, &comparison.Error)
+				res.NewlyUnplannable = append(res.NewlyUnplannable, comparison)
+			}
+			res.Changed = append(res.Changed, comparison)
+		}
+	}
+
+	for key, weight := range transitions {
+		res.Transitions = append(res.Transitions, Transition{From: key[0], To: key[1], Weight: *weight})
+	}
+	sort.Slice(res.Transitions, func(i, j int) bool {
+		a, b := res.Transitions[i], res.Transitions[j]
+		if a.Executions != b.Executions {
+			return a.Executions > b.Executions
+		}
+		if a.From != b.From {
+			return a.From < b.From
+		}
+		return a.To < b.To
+	})
+	byUsage := func(list []QueryComparison) func(i, j int) bool {
+		return func(i, j int) bool {
+			if list[i].UsageCount != list[j].UsageCount {
+				return list[i].UsageCount > list[j].UsageCount
+			}
+			return list[i].QueryStructure < list[j].QueryStructure
+		}
+	}
+	sort.Slice(res.Changed, byUsage(res.Changed))
+	sort.Slice(res.NewlyUnplannable, byUsage(res.NewlyUnplannable))
+	return res
+}
+
+func ReadCompareFile(filename string) (p CompareOutput, err error) {
+	c, err := os.ReadFile(filename)
+	if err != nil {
+		return p, fmt.Errorf("error opening file: %w", err)
+	}
+
+	err = json.Unmarshal(c, &p)
+	if err != nil {
+		return p, fmt.Errorf("error parsing json: %w", err)
+	}
+	return p, nil
+}
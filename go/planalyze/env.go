@@ -0,0 +1,82 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package planalyze
+
+import (
+	"vitess.io/vitess/go/test/vschemawrapper"
+	vschemapb "vitess.io/vitess/go/vt/proto/vschema"
+	"vitess.io/vitess/go/vt/vtenv"
+	"vitess.io/vitess/go/vt/vtgate/vindexes"
+
+	"github.com/vitessio/vt/go/data"
+)
+
+// PlanEnv is everything besides the vschema that the queries are planned with.
+// The same PlanEnv is used for both vschemas when comparing them, and for all the candidates of vt recommend.
+type PlanEnv struct {
+	env           *vtenv.Environment
+	schema        tableColumns
+	fkMode        vschemapb.Keyspace_ForeignKeyMode
+	sysVarEnabled bool
+}
+
+// NewPlanEnv reads the column lists of the tables from a dbinfo file or a schema dump, when given,
+// and sets up the planner for the MySQL version and settings of the options
+func NewPlanEnv(dbInfoFile, schemaFile string, planner data.PlannerOptions) (*PlanEnv, error) {
+	env, err := planner.Environment()
+	if err != nil {
+		return nil, err
+	}
+	fkMode, err := planner.ForeignKeys()
+	if err != nil {
+		return nil, err
+	}
+	schema, err := loadSchema(dbInfoFile, schemaFile, env)
+	if err != nil {
+		return nil, err
+	}
+	return &PlanEnv{
+		env:           env,
+		schema:        schema,
+		fkMode:        fkMode,
+		sysVarEnabled: !planner.DisableSystemSettings,
+	}, nil
+}
+
+// vschemaWrapper prepares a vschema to plan queries with
+func (pe *PlanEnv) vschemaWrapper(vschema *vindexes.VSchema) (*vschemawrapper.VSchemaWrapper, error) {
+	pe.apply(vschema)
+	vw, err := vschemawrapper.NewVschemaWrapper(pe.env, vschema, nil)
+	if err != nil {
+		return nil, err
+	}
+	vw.SysVarEnabled = pe.sysVarEnabled
+	return vw, nil
+}
+
+// apply adds the schema to the vschema and overrides the foreign key mode of its keyspaces.
+// Building the vschema already replaced unspecified modes with unmanaged, so a mode given
+// on the command line wins over the one in the vschema file.
+func (pe *PlanEnv) apply(vschema *vindexes.VSchema) {
+	addColumns(vschema, pe.schema)
+	if pe.fkMode == vschemapb.Keyspace_unspecified {
+		return
+	}
+	for _, ks := range vschema.Keyspaces {
+		ks.ForeignKeyMode = pe.fkMode
+	}
+}
@@ -0,0 +1,60 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package planalyze
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	vschemapb "vitess.io/vitess/go/vt/proto/vschema"
+	"vitess.io/vitess/go/vt/vtgate/engine"
+
+	"github.com/vitessio/vt/go/data"
+)
+
+func TestPlanEnv(t *testing.T) {
+	planSet := func(opts data.PlannerOptions) (*PlanEnv, string) {
+		pe, err := NewPlanEnv("", "", opts)
+		require.NoError(t, err)
+		vw, err := loadVSchema("../testdata/planalyze-vschema-customers.json", "", pe)
+		require.NoError(t, err)
+
+		mode, err := vw.ForeignKeyMode("main")
+		require.NoError(t, err)
+		assert.Equal(t, pe.fkMode == vschemapb.Keyspace_managed, mode == vschemapb.Keyspace_managed)
+
+		plan, err := buildPlan("set sql_mode = ''", vw)
+		require.NoError(t, err)
+		description, err := json.Marshal(engine.PrimitiveToPlanDescription(plan.Instructions, nil))
+		require.NoError(t, err)
+		return pe, string(description)
+	}
+
+	pe, defaultPlan := planSet(data.PlannerOptions{})
+	assert.Equal(t, vschemapb.Keyspace_unspecified, pe.fkMode)
+
+	pe, plan := planSet(data.PlannerOptions{MySQLVersion: "8.4.3", ForeignKeyMode: "managed", DisableSystemSettings: true})
+	assert.Equal(t, "8.4.3", pe.env.MySQLVersion())
+	assert.Equal(t, vschemapb.Keyspace_managed, pe.fkMode)
+	assert.False(t, pe.sysVarEnabled)
+	assert.NotEqual(t, defaultPlan, plan)
+
+	_, err := NewPlanEnv("", "", data.PlannerOptions{MySQLVersion: "eight"})
+	require.Error(t, err)
+}
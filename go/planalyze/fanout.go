@@ -0,0 +1,180 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package planalyze
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"vitess.io/vitess/go/mysql/collations"
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/key"
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	"vitess.io/vitess/go/vt/vtenv"
+	"vitess.io/vitess/go/vt/vtgate/engine"
+	"vitess.io/vitess/go/vt/vtgate/evalengine"
+	"vitess.io/vitess/go/vt/vtgate/vindexes"
+)
+
+// DefaultShards is the number of shards the fan-out of sampled queries is computed for
+const DefaultShards = 4
+
+// ShardFanOut is one bucket of the fan-out distribution of a query structure:
+// how many of the sampled executions were sent to that many shards
+type ShardFanOut struct {
+	Shards  int
+	Samples int
+}
+
+// fanOut routes every sample of bind variables through the plan and returns the distribution
+// of the number of shards the widest route of the plan is sent to
+func fanOut(env *vtenv.Environment, plan engine.Primitive, samples []map[string]*querypb.BindVariable, shards int) []ShardFanOut {
+	if shards <= 0 || len(samples) == 0 {
+		return nil
+	}
+
+	var res []ShardFanOut
+	for _, bv := range samples {
+		n := planShards(env, plan, bv, shards)
+		idx := slices.IndexFunc(res, func(f ShardFanOut) bool { return f.Shards == n })
+		if idx < 0 {
+			res = append(res, ShardFanOut{Shards: n})
+			idx = len(res) - 1
+		}
+		res[idx].Samples++
+	}
+	slices.SortFunc(res, func(a, b ShardFanOut) int { return a.Shards - b.Shards })
+	return res
+}
+
+// planShards returns the largest number of shards one primitive of the plan is sent to with the given bind variables
+func planShards(env *vtenv.Environment, plan engine.Primitive, bv map[string]*querypb.BindVariable, shards int) int {
+	r := &shardResolver{
+		env:       evalengine.NewExpressionEnv(context.Background(), bv, evalengine.NewEmptyVCursor(env, time.Local)),
+		collation: env.CollationEnv().DefaultConnectionCharset(),
+		shards:    shards,
+	}
+
+	widest := 0
+	var walk func(p engine.Primitive)
+	walk = func(p engine.Primitive) {
+		switch prim := p.(type) {
+		case *engine.Route:
+			widest = max(widest, r.route(prim.RoutingParameters))
+		case *engine.Update:
+			widest = max(widest, r.route(prim.RoutingParameters))
+		case *engine.Delete:
+			widest = max(widest, r.route(prim.RoutingParameters))
+		case *engine.Insert:
+			widest = max(widest, r.insert(prim))
+		}
+
+		inputs, _ := p.Inputs()
+		for _, input := range inputs {
+			walk(input)
+		}
+	}
+	walk(plan)
+	return widest
+}
+
+// shardResolver maps the values of a route to shards, assuming the keyspace is split in equal key ranges.
+// Whenever the values cannot be resolved, like with vindexes that need to query a lookup table,
+// it assumes the worst case of sending the query to all the shards.
+type shardResolver struct {
+	env       *evalengine.ExpressionEnv
+	collation collations.ID
+	shards    int
+}
+
+func (r *shardResolver) route(rp *engine.RoutingParameters) int {
+	if rp.Keyspace != nil && !rp.Keyspace.Sharded {
+		return 1
+	}
+
+	switch rp.Opcode {
+	case engine.None:
+		return 0
+	case engine.Unsharded, engine.Next, engine.DBA, engine.Reference:
+		return 1
+	case engine.Equal, engine.EqualUnique:
+		value, ok := r.evaluate(rp.Values)
+		if !ok {
+			return r.shards
+		}
+		return r.count(rp.Vindex, []sqltypes.Value{value.Value(r.collation)})
+	case engine.IN, engine.MultiEqual:
+		value, ok := r.evaluate(rp.Values)
+		if !ok {
+			return r.shards
+		}
+		return r.count(rp.Vindex, value.TupleValues())
+	default:
+		return r.shards
+	}
+}
+
+func (r *shardResolver) insert(ins *engine.Insert) int {
+	if ins.Opcode == engine.InsertUnsharded {
+		return 1
+	}
+	if len(ins.VindexValues) == 0 || len(ins.VindexValues[0]) != 1 {
+		// only single column primary vindexes are resolved
+		return r.shards
+	}
+
+	var values []sqltypes.Value
+	for _, expr := range ins.VindexValues[0][0] {
+		v, err := r.env.Evaluate(expr)
+		if err != nil {
+			return r.shards
+		}
+		values = append(values, v.Value(r.collation))
+	}
+	return r.count(ins.ColVindexes[0].Vindex, values)
+}
+
+func (r *shardResolver) evaluate(values []evalengine.Expr) (evalengine.EvalResult, bool) {
+	if len(values) != 1 {
+		return evalengine.EvalResult{}, false
+	}
+	v, err := r.env.Evaluate(values[0])
+	return v, err == nil
+}
+
+// count maps the values with the vindex and counts the distinct shards they land on
+func (r *shardResolver) count(vindex vindexes.Vindex, values []sqltypes.Value) int {
+	single, ok := vindex.(vindexes.SingleColumn)
+	if !ok || vindex.NeedsVCursor() {
+		return r.shards
+	}
+	destinations, err := single.Map(context.Background(), nil, values)
+	if err != nil {
+		return r.shards
+	}
+
+	hit := make(map[int]bool)
+	for _, dest := range destinations {
+		ksid, ok := dest.(key.DestinationKeyspaceID)
+		if !ok || len(ksid) == 0 {
+			return r.shards
+		}
+		hit[int(ksid[0])*r.shards/256] = true
+	}
+	return min(len(hit), r.shards)
+}
@@ -0,0 +1,149 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package planalyze
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ErrGateFailed is returned when the gate finds queries whose plans got worse, or a share above its threshold
+var ErrGateFailed = errors.New("planalyze gate failed")
+
+// GateConfig turns planalyze into a regression gate for CI.
+// Instead of the JSON output, a short report is printed and ErrGateFailed is returned when the checks fail.
+type GateConfig struct {
+	// BaselineFile is a previous planalyze output. Every query that is also in the baseline
+	// must have the same or a better complexity.
+	BaselineFile string
+
+	// MaxComplexShare and MaxUnplannableShare are the highest percentages of the executions
+	// that may be complex or unplannable. Nil means no threshold.
+	MaxComplexShare     *float64
+	MaxUnplannableShare *float64
+}
+
+func (g GateConfig) enabled() bool {
+	return g.BaselineFile != "" || g.MaxComplexShare != nil || g.MaxUnplannableShare != nil
+}
+
+// FromOutput turns a planalyze output back into the analysis it was written from.
+// The complexity of a query is the one of the list it is in.
+func FromOutput(o Output) *Planalyze {
+	p := &Planalyze{
+		Queries: [Unplannable + 1][]AnalyzedQuery{o.PassThrough, o.SimpleRouted, o.Scatter, o.Complex, o.Unplannable},
+	}
+	for complexity, queries := range p.Queries {
+		p.Queries[complexity] = make([]AnalyzedQuery, len(queries))
+		for i, q := range queries {
+			q.Complexity = PlanComplexity(complexity)
+			p.Queries[complexity][i] = q
+		}
+	}
+	return p
+}
+
+func runGate(out io.Writer, cfg GateConfig, current *Planalyze) error {
+	failed := false
+	md := &strings.Builder{}
+
+	if cfg.BaselineFile != "" {
+		baseline, err := ReadPlanalyzeFile(cfg.BaselineFile)
+		if err != nil {
+			return fmt.Errorf("could not read the baseline: %w", err)
+		}
+		failed = writeBaselineDiff(md, cfg.BaselineFile, FromOutput(baseline), current)
+	}
+
+	totals := current.Totals()
+	if cfg.MaxComplexShare != nil || cfg.MaxUnplannableShare != nil {
+		md.WriteString("\n")
+	}
+	failed = writeShare(md, totals, Complex, cfg.MaxComplexShare) || failed
+	failed = writeShare(md, totals, Unplannable, cfg.MaxUnplannableShare) || failed
+
+	result := "passed"
+	if failed {
+		result = "FAILED"
+	}
+	if _, err := fmt.Fprintf(out, "Planalyze gate %s\n%s", result, md.String()); err != nil {
+		return err
+	}
+	if failed {
+		return ErrGateFailed
+	}
+	return nil
+}
+
+// writeBaselineDiff lists the queries whose complexity changed since the baseline, and reports whether any got worse
+func writeBaselineDiff(w io.Writer, baselineFile string, baseline, current *Planalyze) bool {
+	comparison := Compare(baseline, current)
+
+	var regressions, improvements []QueryComparison
+	for _, q := range comparison.Changed {
+		if q.After > q.Before {
+			regressions = append(regressions, q)
+		} else {
+			improvements = append(improvements, q)
+		}
+	}
+
+	fmt.Fprintf(w, "\nBaseline %s: %d regressions, %d improvements\n", baselineFile, len(regressions), len(improvements))
+	for _, q := range regressions {
+		fmt.Fprintf(w, "  %s -> %s (%d executions): %s\n", q.Before, q.After, q.UsageCount, q.QueryStructure)
+		if q.Error != "" {
+			fmt.Fprintf(w, "    %s\n", q.Error)
+		}
+	}
+	return len(regressions) > 0
+}
+
+// writeShare checks the share of the executions with the given complexity against the threshold.
+// Files without usage counts are weighted by query structure instead.
+func writeShare(w io.Writer, totals ComplexityTotals, c PlanComplexity, threshold *float64) bool {
+	if threshold == nil {
+		return false
+	}
+
+	var total int
+	for _, complexity := range Complexities() {
+		total += totals.Get(complexity).Executions
+	}
+	part := totals.Get(c).Executions
+	unit := "executions"
+	if total == 0 {
+		for _, complexity := range Complexities() {
+			total += totals.Get(complexity).Queries
+		}
+		part = totals.Get(c).Queries
+		unit = "queries"
+	}
+
+	share := 0.0
+	if total > 0 {
+		share = float64(part) * 100 / float64(total)
+	}
+	exceeded := share > *threshold
+	status := "ok"
+	if exceeded {
+		status = "exceeded"
+	}
+	fmt.Fprintf(w, "%s: %.1f%% of %s, threshold %.1f%%, %s\n", c, share, unit, *threshold, status)
+	return exceeded
+}
@@ -0,0 +1,73 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package planalyze
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunGate(t *testing.T) {
+	baseline := filepath.Join(t.TempDir(), "baseline.json")
+	sb := &strings.Builder{}
+	err := run(sb, Config{VSchemaFile: "../testdata/planalyze-vschema-customers-colocated.json"}, "../testdata/keys-output/keys-log-vtgate.json")
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(baseline, []byte(sb.String()), 0o644))
+
+	// the same vschema as the baseline passes
+	sb.Reset()
+	cfg := Config{
+		VSchemaFile: "../testdata/planalyze-vschema-customers-colocated.json",
+		Gate:        GateConfig{BaselineFile: baseline},
+	}
+	require.NoError(t, run(sb, cfg, "../testdata/keys-output/keys-log-vtgate.json"))
+	assert.True(t, strings.HasPrefix(sb.String(), "Planalyze gate passed\n"))
+
+	// without the colocated orders table, the joins become cross-shard
+	sb.Reset()
+	maxComplex, maxUnplannable := 10.0, 5.0
+	cfg = Config{
+		VSchemaFile: "../testdata/planalyze-vschema-customers.json",
+		Gate: GateConfig{
+			BaselineFile:        baseline,
+			MaxComplexShare:     &maxComplex,
+			MaxUnplannableShare: &maxUnplannable,
+		},
+	}
+	err = run(sb, cfg, "../testdata/keys-output/keys-log-vtgate.json")
+	require.ErrorIs(t, err, ErrGateFailed)
+	out := sb.String()
+	assert.True(t, strings.HasPrefix(out, "Planalyze gate FAILED\n"))
+	assert.Contains(t, out, ": 2 regressions, 0 improvements\n")
+	assert.Contains(t, out, "  Scatter -> Complex routed (1 executions): SELECT `c`.`customer_id`, sum(`o`.`order_amount`)")
+	assert.Contains(t, out, "\nComplex routed: 95.2% of executions, threshold 10.0%, exceeded\nUnplannable: 0.0% of executions, threshold 5.0%, ok\n")
+}
+
+func TestRunGateWithCompare(t *testing.T) {
+	cfg := Config{
+		VSchemaFile:        "../testdata/planalyze-vschema-customers.json",
+		CompareVSchemaFile: "../testdata/planalyze-vschema-customers-colocated.json",
+		Gate:               GateConfig{BaselineFile: "baseline.json"},
+	}
+	err := run(&strings.Builder{}, cfg, "../testdata/keys-output/keys-log-vtgate.json")
+	require.ErrorContains(t, err, "cannot be used together")
+}
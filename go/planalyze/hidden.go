@@ -0,0 +1,150 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package planalyze
+
+import (
+	"vitess.io/vitess/go/vt/vtgate/engine"
+)
+
+type (
+	// HiddenOperations are the statements vtgate runs on top of a write to keep vindexes and sequences up to date.
+	// The counts are for one execution of the query, assuming UPDATE and DELETE change a single row.
+	HiddenOperations struct {
+		// LookupReads are the queries to lookup tables, to route the statement or to verify a vindex that is not owned
+		LookupReads int
+		// LookupWrites are the inserts and deletes in the lookup tables of owned vindexes, one per vindex and statement
+		LookupWrites int
+		// OwnedVindexReads are the selects of the rows an UPDATE or DELETE changes, to find the lookup entries to maintain
+		OwnedVindexReads int
+		// SequenceFetches are the calls to a sequence to generate auto-increment values
+		SequenceFetches int
+	}
+
+	// WriteAmplification sums up the hidden operations of all the writes, weighted by usage
+	WriteAmplification struct {
+		// Writes is the number of executions of INSERT, UPDATE and DELETE statements
+		Writes int              `json:"writes"`
+		Hidden HiddenOperations `json:"hidden"`
+		// Factor is the number of statements run per write, counting the write itself
+		Factor float64 `json:"factor"`
+	}
+)
+
+func (h HiddenOperations) Total() int {
+	return h.LookupReads + h.LookupWrites + h.OwnedVindexReads + h.SequenceFetches
+}
+
+func (h *HiddenOperations) add(other HiddenOperations, times int) {
+	h.LookupReads += other.LookupReads * times
+	h.LookupWrites += other.LookupWrites * times
+	h.OwnedVindexReads += other.OwnedVindexReads * times
+	h.SequenceFetches += other.SequenceFetches * times
+}
+
+// WriteAmplification sums up the hidden operations of the writes, or returns nil if there are no writes
+func (p *Planalyze) WriteAmplification() *WriteAmplification {
+	var wa WriteAmplification
+	for _, queries := range p.Queries {
+		for _, q := range queries {
+			if q.Hidden == nil {
+				continue
+			}
+			executions := max(q.UsageCount, 1)
+			wa.Writes += executions
+			wa.Hidden.add(*q.Hidden, executions)
+		}
+	}
+	if wa.Writes == 0 {
+		return nil
+	}
+	wa.Factor = float64(wa.Writes+wa.Hidden.Total()) / float64(wa.Writes)
+	return &wa
+}
+
+// hiddenOperations walks the plan and counts the statements vtgate runs to maintain vindexes and sequences.
+// It returns nil when the plan does not write.
+func hiddenOperations(plan engine.Primitive) *HiddenOperations {
+	var ops HiddenOperations
+	write := false
+
+	var walk func(p engine.Primitive)
+	walk = func(p engine.Primitive) {
+		switch prim := p.(type) {
+		case *engine.Insert:
+			write = true
+			rows := 1
+			if len(prim.VindexValues) > 0 && len(prim.VindexValues[0]) > 0 {
+				rows = len(prim.VindexValues[0][0])
+			}
+			insertOperations(&prim.InsertCommon, rows, &ops)
+		case *engine.InsertSelect:
+			write = true
+			insertOperations(&prim.InsertCommon, 1, &ops)
+		case *engine.Update:
+			write = true
+			dmlOperations(prim.DML, &ops)
+			// changing a vindex column deletes the old lookup entry and inserts the new one
+			ops.LookupWrites += 2 * len(prim.ChangedVindexValues)
+		case *engine.Delete:
+			write = true
+			dmlOperations(prim.DML, &ops)
+			if prim.OwnedVindexQuery != "" {
+				ops.LookupWrites += len(prim.Vindexes)
+			}
+		case *engine.VindexLookup:
+			ops.LookupReads++
+		}
+
+		inputs, _ := p.Inputs()
+		for _, input := range inputs {
+			walk(input)
+		}
+	}
+	walk(plan)
+
+	if !write {
+		return nil
+	}
+	return &ops
+}
+
+func insertOperations(ic *engine.InsertCommon, rows int, ops *HiddenOperations) {
+	if ic.Generate != nil {
+		ops.SequenceFetches++
+	}
+
+	// the first vindex is the primary vindex. The entries of an owned vindex are inserted in a single statement,
+	// while a vindex that is not owned is verified for each row
+	for i, cv := range ic.ColVindexes {
+		switch {
+		case i == 0:
+		case cv.Owned:
+			ops.LookupWrites++
+		case cv.Vindex.NeedsVCursor():
+			ops.LookupReads += rows
+		}
+	}
+}
+
+func dmlOperations(dml *engine.DML, ops *HiddenOperations) {
+	if dml.OwnedVindexQuery != "" {
+		ops.OwnedVindexReads++
+	}
+	if dml.RoutingParameters != nil && dml.Vindex != nil && dml.Vindex.NeedsVCursor() {
+		ops.LookupReads++
+	}
+}
@@ -0,0 +1,44 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package planalyze
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteAmplification(t *testing.T) {
+	p := &Planalyze{Queries: [Unplannable + 1][]AnalyzedQuery{
+		PassThrough: {
+			{QueryStructure: "update", UsageCount: 3, Hidden: &HiddenOperations{LookupWrites: 2, OwnedVindexReads: 1}},
+			{QueryStructure: "select", UsageCount: 100},
+		},
+		SimpleRouted: {
+			{QueryStructure: "insert", UsageCount: 1, Hidden: &HiddenOperations{SequenceFetches: 1}},
+		},
+	}}
+
+	wa := p.WriteAmplification()
+	require.NotNil(t, wa)
+	assert.Equal(t, 4, wa.Writes)
+	assert.Equal(t, HiddenOperations{LookupWrites: 6, OwnedVindexReads: 3, SequenceFetches: 1}, wa.Hidden)
+	assert.InDelta(t, 3.5, wa.Factor, 0.001)
+
+	assert.Nil(t, (&Planalyze{}).WriteAmplification())
+}
@@ -18,6 +18,7 @@ package planalyze
 
 import (
 	"bytes"
+	"cmp"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -25,9 +26,10 @@ import (
 	"os"
 
 	"vitess.io/vitess/go/test/vschemawrapper"
-	"vitess.io/vitess/go/vt/vtenv"
+	vschemapb "vitess.io/vitess/go/vt/proto/vschema"
 	"vitess.io/vitess/go/vt/vtgate/engine"
 	"vitess.io/vitess/go/vt/vtgate/planbuilder"
+	"vitess.io/vitess/go/vt/vtgate/vindexes"
 
 	"github.com/vitessio/vt/go/data"
 	"github.com/vitessio/vt/go/keys"
@@ -37,44 +39,108 @@ type (
 	Config struct {
 		VSchemaFile          string
 		VtExplainVschemaFile string
+
+		// CompareVSchemaFile is a second vschema, in the same format as the first one.
+		// When set, every query is planned with both and the differences are reported.
+		CompareVSchemaFile string
+
+		// DBInfoFile and SchemaFile supply the columns of the tables, either as the output of `vt dbinfo` or as an SQL schema dump.
+		// Without them, tables have no column lists and queries that need them to be planned are reported as missing schema info.
+		DBInfoFile string
+		SchemaFile string
+
+		// Loader reads the positional argument as a query log instead of a keys file.
+		// The queries are parameterized, so all the executions of a query shape share one query structure.
+		Loader data.Loader
+
+		// Samples is the number of executions per query structure that are routed with their own bind variables
+		// to report the shard fan-out distribution. It needs a Loader, as keys files do not contain the values.
+		Samples int
+		// Shards is the number of shards the fan-out is computed for
+		Shards int
+
+		Gate GateConfig
+
+		// Planner selects the MySQL version and the vtgate settings the queries are planned for
+		Planner data.PlannerOptions
 	}
 
 	// Planalyze is the main struct for the planalyze tool.
-	// It is a size four slice as we have four different types of analyzed queries
+	// It has a slice of analyzed queries for each complexity, indexed by PlanComplexity
 	Planalyze struct {
-		Queries [4][]AnalyzedQuery
+		Queries [Unplannable + 1][]AnalyzedQuery
 	}
 
 	Output struct {
 		FileType     string          `json:"fileType"`
 		PassThrough  []AnalyzedQuery `json:"passThrough"`
 		SimpleRouted []AnalyzedQuery `json:"simpleRouted"`
+		Scatter      []AnalyzedQuery `json:"scatter"`
 		Complex      []AnalyzedQuery `json:"complex"`
 		Unplannable  []AnalyzedQuery `json:"unplannable"`
+
+		WriteAmplification *WriteAmplification `json:"writeAmplification,omitempty"`
+
+		// Shards is the number of shards the fan-out of the sampled queries was computed for
+		Shards int `json:"shards,omitempty"`
 	}
 
 	AnalyzedQuery struct {
 		QueryStructure string
 		Complexity     PlanComplexity
-		PlanOutput     json.RawMessage
+
+		// UsageCount, QueryTime and RowsExamined are copied from the keys output,
+		// so results can be weighted by how much of the workload a query represents
+		UsageCount   int
+		QueryTime    float64
+		RowsExamined int
+
+		// Reasons are the operations in the plan that make it more expensive than a single-shard route
+		Reasons []Reason `json:",omitempty"`
+
+		// Cause is only set for unplannable queries
+		Cause UnplannableCause `json:",omitempty"`
+
+		// Hidden is only set for writes, and counts the extra statements needed to maintain vindexes and sequences
+		Hidden *HiddenOperations `json:",omitempty"`
+
+		// FanOut is the distribution of the number of shards the sampled executions of the query were sent to
+		FanOut []ShardFanOut `json:",omitempty"`
+
+		// Summary is the shape of the plan in PlanOutput, it is not set for unplannable queries
+		Summary *PlanSummary `json:",omitempty"`
+
+		PlanOutput json.RawMessage
 	}
 
 	PlanComplexity int
 )
 
 const (
+	// PassThrough is a single route to a single shard
 	PassThrough PlanComplexity = iota
+	// SimpleRouted is a single route to the shards targeted by its vindex values, like an IN on the sharding key
 	SimpleRouted
+	// Scatter is a single route to all the shards
+	Scatter
+	// Complex needs vtgate to do some of the work, like joining or aggregating the results of routes
 	Complex
 	Unplannable
 )
 
+// Complexities lists the complexities from the simplest to unplannable
+func Complexities() []PlanComplexity {
+	return []PlanComplexity{PassThrough, SimpleRouted, Scatter, Complex, Unplannable}
+}
+
 func (p PlanComplexity) String() string {
 	switch p {
 	case PassThrough:
 		return "Pass-through"
 	case SimpleRouted:
 		return "Simple routed"
+	case Scatter:
+		return "Scatter"
 	case Complex:
 		return "Complex routed"
 	case Unplannable:
@@ -83,6 +149,34 @@ func (p PlanComplexity) String() string {
 	return "Unknown"
 }
 
+// MarshalJSON writes the name of the complexity, so that the files do not depend on the order of the constants
+func (p PlanComplexity) MarshalJSON() ([]byte, error) {
+	return json.Marshal(p.String())
+}
+
+// UnmarshalJSON reads the name of a complexity. Files written before scatter routes had their own complexity
+// have numbers instead, where complex routed was 2 and unplannable 3.
+func (p *PlanComplexity) UnmarshalJSON(b []byte) error {
+	var name string
+	if err := json.Unmarshal(b, &name); err == nil {
+		for _, c := range Complexities() {
+			if c.String() == name {
+				*p = c
+				return nil
+			}
+		}
+		return fmt.Errorf("unknown plan complexity %q", name)
+	}
+
+	legacy := []PlanComplexity{PassThrough, SimpleRouted, Complex, Unplannable}
+	var n int
+	if err := json.Unmarshal(b, &n); err != nil || n < 0 || n >= len(legacy) {
+		return fmt.Errorf("invalid plan complexity %s", b)
+	}
+	*p = legacy[n]
+	return nil
+}
+
 func Run(cfg Config, logFile string) error {
 	return run(os.Stdout, cfg, logFile)
 }
@@ -93,47 +187,123 @@ func run(out io.Writer, cfg Config, logFile string) error {
 	if a == b {
 		return errors.New("specify exactly one of the following flags: -vschema or -vtexplain-vschema")
 	}
+	if cfg.CompareVSchemaFile != "" && cfg.Gate.enabled() {
+		return errors.New("the gate cannot be used together with -compare-vschema")
+	}
 
-	_, vschema, err := data.GetKeyspaces(cfg.VSchemaFile, cfg.VtExplainVschemaFile, "main", false)
+	pe, err := NewPlanEnv(cfg.DBInfoFile, cfg.SchemaFile, cfg.Planner)
 	if err != nil {
 		return err
 	}
 
-	ko, err := keys.ReadKeysFile(logFile)
+	vw, err := loadVSchema(cfg.VSchemaFile, cfg.VtExplainVschemaFile, pe)
 	if err != nil {
 		return err
 	}
 
-	vw, err := vschemawrapper.NewVschemaWrapper(vtenv.NewTestEnv(), vschema, nil)
+	queries, err := readQueries(cfg, logFile)
 	if err != nil {
 		return err
 	}
 
+	shards := 0
+	if cfg.Samples > 0 {
+		shards = cmp.Or(cfg.Shards, DefaultShards)
+	}
+	planalyzer, err := analyze(vw, queries, shards)
+	if err != nil {
+		return err
+	}
+
+	if cfg.CompareVSchemaFile != "" {
+		return runCompare(out, cfg, pe, queries, planalyzer)
+	}
+	if cfg.Gate.enabled() {
+		return runGate(out, cfg.Gate, planalyzer)
+	}
+
+	res := Output{
+		FileType:     "planalyze",
+		PassThrough:  planalyzer.Queries[PassThrough],
+		SimpleRouted: planalyzer.Queries[SimpleRouted],
+		Scatter:      planalyzer.Queries[Scatter],
+		Complex:      planalyzer.Queries[Complex],
+		Unplannable:  planalyzer.Queries[Unplannable],
+
+		WriteAmplification: planalyzer.WriteAmplification(),
+		Shards:             shards,
+	}
+	return writeJSON(out, res)
+}
+
+// readQueries reads the keys file, or runs the keys analysis on the query log when a loader is configured
+func readQueries(cfg Config, logFile string) ([]keys.QueryAnalysisResult, error) {
+	if cfg.Loader == nil {
+		if cfg.Samples > 0 {
+			return nil, errors.New("sampling bind variables needs a query log, keys files do not contain them")
+		}
+		ko, err := keys.ReadKeysFile(logFile)
+		return ko.Queries, err
+	}
+
+	ko, err := keys.Analyze(keys.Config{
+		FileName:     logFile,
+		Loader:       cfg.Loader,
+		Parameterize: true,
+		Samples:      cfg.Samples,
+		Planner:      cfg.Planner,
+	})
+	return ko.Queries, err
+}
+
+func loadVSchema(vschemaFile, vtexplainVSchemaFile string, pe *PlanEnv) (*vschemawrapper.VSchemaWrapper, error) {
+	_, vschema, err := data.GetKeyspaces(vschemaFile, vtexplainVSchemaFile, "main", false)
+	if err != nil {
+		return nil, err
+	}
+	return pe.vschemaWrapper(vschema)
+}
+
+// Analyze plans the queries with a vschema built in memory,
+// so callers can evaluate many vschemas without writing them to disk
+func Analyze(srvVSchema *vschemapb.SrvVSchema, queries []keys.QueryAnalysisResult, pe *PlanEnv) (*Planalyze, error) {
+	vschema := vindexes.BuildVSchema(srvVSchema, pe.env.Parser())
+	for name, ks := range vschema.Keyspaces {
+		if ks.Error != nil {
+			return nil, fmt.Errorf("invalid vschema for keyspace %s: %w", name, ks.Error)
+		}
+	}
+
+	vw, err := pe.vschemaWrapper(vschema)
+	if err != nil {
+		return nil, err
+	}
+	return analyze(vw, queries, 0)
+}
+
+// analyze plans all the queries and groups them by the complexity of their plans.
+// When shards is set, the sampled bind variables of the queries are routed to compute their fan-out.
+func analyze(vw *vschemawrapper.VSchemaWrapper, queries []keys.QueryAnalysisResult, shards int) (*Planalyze, error) {
 	planalyzer := &Planalyze{
-		Queries: [4][]AnalyzedQuery{
-			{},
-			{},
-			{},
-			{},
-		},
+		Queries: [Unplannable + 1][]AnalyzedQuery{},
+	}
+	for complexity := range planalyzer.Queries {
+		planalyzer.Queries[complexity] = []AnalyzedQuery{}
 	}
 
-	for _, query := range ko.Queries {
-		var plan *engine.Plan
-		plan, err = planbuilder.TestBuilder(query.QueryStructure, vw, "")
+	for _, query := range queries {
+		plan, err := buildPlan(query.QueryStructure, vw)
 
 		res := getPlanRes(err, plan)
 		switch {
 		case res == Unplannable:
 			errBytes, jsonErr := json.Marshal(err.Error())
 			if jsonErr != nil {
-				return jsonErr
+				return nil, jsonErr
 			}
-			planalyzer.Queries[res] = append(planalyzer.Queries[res], AnalyzedQuery{
-				QueryStructure: query.QueryStructure,
-				Complexity:     res,
-				PlanOutput:     errBytes,
-			})
+			aq := newAnalyzedQuery(query, res, errBytes)
+			aq.Cause = unplannableCause(err)
+			planalyzer.Queries[res] = append(planalyzer.Queries[res], aq)
 		case plan.Instructions != nil:
 			description := engine.PrimitiveToPlanDescription(plan.Instructions, nil)
 			b := new(bytes.Buffer)
@@ -142,59 +312,69 @@ func run(out io.Writer, cfg Config, logFile string) error {
 			enc.SetIndent("", "  ")
 			err = enc.Encode(description)
 			if err != nil {
-				return err
+				return nil, err
 			}
-			planalyzer.Queries[res] = append(planalyzer.Queries[res], AnalyzedQuery{
-				QueryStructure: query.QueryStructure,
-				Complexity:     res,
-				PlanOutput:     json.RawMessage(b.String()),
-			})
+			aq := newAnalyzedQuery(query, res, json.RawMessage(b.String()))
+			aq.Reasons = planReasons(plan.Instructions)
+			aq.Hidden = hiddenOperations(plan.Instructions)
+			aq.FanOut = fanOut(vw.Env, plan.Instructions, query.Samples, shards)
+			aq.Summary = summarizePlan(description)
+			planalyzer.Queries[res] = append(planalyzer.Queries[res], aq)
 		default:
 			// if we don't have an instruction, this query is not interesting for planalyze
 		}
 	}
+	return planalyzer, nil
+}
 
-	type jsonOutput struct {
-		FileType     string          `json:"fileType"`
-		PassThrough  []AnalyzedQuery `json:"passThrough"`
-		SimpleRouted []AnalyzedQuery `json:"simpleRouted"`
-		Complex      []AnalyzedQuery `json:"complex"`
-		Unplannable  []AnalyzedQuery `json:"unplannable"`
-	}
-	res := jsonOutput{
-		FileType:     "planalyze",
-		PassThrough:  planalyzer.Queries[PassThrough],
-		SimpleRouted: planalyzer.Queries[SimpleRouted],
-		Complex:      planalyzer.Queries[Complex],
-		Unplannable:  planalyzer.Queries[Unplannable],
-	}
+// buildPlan plans a query, turning a panic of the planner into an error,
+// so one query the planner cannot handle does not stop the analysis of the others
+func buildPlan(query string, vw *vschemawrapper.VSchemaWrapper) (plan *engine.Plan, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			plan, err = nil, fmt.Errorf("planner panic: %v", r)
+		}
+	}()
+	return planbuilder.TestBuilder(query, vw, "")
+}
 
+func writeJSON(out io.Writer, res any) error {
 	jsonData, err := json.MarshalIndent(res, "  ", "  ")
 	if err != nil {
 		return err
 	}
 	_, err = out.Write(jsonData)
-	if err != nil {
-		return err
-	}
+	return err
+}
 
-	return nil
+func newAnalyzedQuery(query keys.QueryAnalysisResult, complexity PlanComplexity, planOutput json.RawMessage) AnalyzedQuery {
+	return AnalyzedQuery{
+		QueryStructure: query.QueryStructure,
+		Complexity:     complexity,
+		UsageCount:     query.UsageCount,
+		QueryTime:      query.QueryTime,
+		RowsExamined:   query.RowsExamined,
+		PlanOutput:     planOutput,
+	}
 }
 
 func getPlanRes(err error, plan *engine.Plan) PlanComplexity {
 	if err != nil {
 		return Unplannable
 	}
+	return planComplexity(plan.Instructions)
+}
 
-	var rp *engine.RoutingParameters
-
-	switch prim := plan.Instructions.(type) {
+// planComplexity walks the plan tree. A plan that only routes the query is classified by the shards it is sent to,
+// any other primitive means vtgate does part of the work, which makes the plan complex
+func planComplexity(p engine.Primitive) PlanComplexity {
+	switch prim := p.(type) {
 	case *engine.Route:
-		rp = prim.RoutingParameters
+		return opcodeComplexity(prim.Opcode)
 	case *engine.Update:
-		rp = prim.RoutingParameters
+		return opcodeComplexity(prim.Opcode)
 	case *engine.Delete:
-		rp = prim.RoutingParameters
+		return opcodeComplexity(prim.Opcode)
 	case *engine.Insert:
 		if prim.Opcode == engine.InsertUnsharded {
 			return PassThrough
@@ -203,12 +383,18 @@ func getPlanRes(err error, plan *engine.Plan) PlanComplexity {
 	default:
 		return Complex
 	}
+}
 
-	if rp.Opcode.IsSingleShard() {
+func opcodeComplexity(opcode engine.Opcode) PlanComplexity {
+	switch {
+	case opcode.IsSingleShard(), opcode == engine.None:
 		return PassThrough
+	case opcode == engine.Scatter:
+		return Scatter
+	default:
+		// IN, MultiEqual, Between, SubShard, ByDestination and Equal on a non-unique vindex go to the shards they target
+		return SimpleRouted
 	}
-
-	return SimpleRouted
 }
 
 func ReadPlanalyzeFile(filename string) (p Output, err error) {
@@ -17,12 +17,16 @@ limitations under the License.
 package planalyze
 
 import (
+	"encoding/json"
 	"os"
 	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"vitess.io/vitess/go/vt/vtgate/engine"
+
+	"github.com/vitessio/vt/go/data"
 )
 
 func TestRun(t *testing.T) {
@@ -42,3 +46,153 @@ func TestRun(t *testing.T) {
 		_ = os.WriteFile("../testdata/expected/bigger_slow_query_plan_report.json", []byte(sb.String()), 0o644)
 	}
 }
+
+func TestRunCompare(t *testing.T) {
+	sb := &strings.Builder{}
+	cfg := Config{
+		VSchemaFile:        "../testdata/planalyze-vschema-customers.json",
+		CompareVSchemaFile: "../testdata/planalyze-vschema-customers-colocated.json",
+	}
+
+	err := run(sb, cfg, "../testdata/keys-output/keys-log-vtgate.json")
+	require.NoError(t, err)
+
+	out, err := os.ReadFile("../testdata/planalyze-output/keys-log-vtgate-compare.json")
+	require.NoError(t, err)
+
+	assert.Equal(t, string(out), sb.String())
+	if t.Failed() {
+		_ = os.Mkdir("../testdata/expected", 0o755)
+		_ = os.WriteFile("../testdata/expected/keys-log-vtgate-compare.json", []byte(sb.String()), 0o644)
+	}
+}
+
+func TestCompare(t *testing.T) {
+	before := &Planalyze{Queries: [Unplannable + 1][]AnalyzedQuery{
+		PassThrough:  {{QueryStructure: "q1", Complexity: PassThrough, UsageCount: 10, QueryTime: 1}},
+		SimpleRouted: {{QueryStructure: "q2", Complexity: SimpleRouted, UsageCount: 5}},
+		Complex:      {{QueryStructure: "q3", Complexity: Complex, UsageCount: 2}},
+	}}
+	after := &Planalyze{Queries: [Unplannable + 1][]AnalyzedQuery{
+		PassThrough:  {{QueryStructure: "q3", Complexity: PassThrough, UsageCount: 2}},
+		SimpleRouted: {{QueryStructure: "q2", Complexity: SimpleRouted, UsageCount: 5}},
+		Unplannable:  {{QueryStructure: "q1", Complexity: Unplannable, UsageCount: 10, QueryTime: 1, PlanOutput: []byte(`"table not found"`)}},
+	}}
+
+	res := Compare(before, after)
+	assert.Equal(t, Weight{Queries: 1, Executions: 10, QueryTime: 1}, res.Before.PassThrough)
+	assert.Equal(t, Weight{Queries: 1, Executions: 10, QueryTime: 1}, res.After.Unplannable)
+	assert.Equal(t, []Transition{
+		{From: PassThrough, To: Unplannable, Weight: Weight{Queries: 1, Executions: 10, QueryTime: 1}},
+		{From: Complex, To: PassThrough, Weight: Weight{Queries: 1, Executions: 2}},
+	}, res.Transitions)
+	require.Len(t, res.Changed, 2)
+	assert.Equal(t, "q1", res.Changed[0].QueryStructure)
+	assert.Equal(t, "q3", res.Changed[1].QueryStructure)
+	require.Len(t, res.NewlyUnplannable, 1)
+	assert.Equal(t, "table not found", res.NewlyUnplannable[0].Error)
+}
+
+func TestRunHiddenOperations(t *testing.T) {
+	sb := &strings.Builder{}
+	cfg := Config{
+		VSchemaFile: "../testdata/planalyze-vschema-lookup.json",
+	}
+
+	err := run(sb, cfg, "../testdata/keys-output/keys-writes.json")
+	require.NoError(t, err)
+
+	out, err := os.ReadFile("../testdata/planalyze-output/keys-writes-plan-report.json")
+	require.NoError(t, err)
+
+	assert.Equal(t, string(out), sb.String())
+	if t.Failed() {
+		_ = os.Mkdir("../testdata/expected", 0o755)
+		_ = os.WriteFile("../testdata/expected/keys-writes-plan-report.json", []byte(sb.String()), 0o644)
+	}
+}
+
+func TestRunFromQueryLog(t *testing.T) {
+	sb := &strings.Builder{}
+	cfg := Config{
+		VSchemaFile: "../testdata/planalyze-vschema-customers.json",
+		Loader:      data.SlowQueryLogLoader{},
+		Samples:     10,
+	}
+
+	err := run(sb, cfg, "../testdata/query-logs/customers-fanout.log")
+	require.NoError(t, err)
+
+	out, err := os.ReadFile("../testdata/planalyze-output/customers-fanout-plan-report.json")
+	require.NoError(t, err)
+
+	assert.Equal(t, string(out), sb.String())
+	if t.Failed() {
+		_ = os.Mkdir("../testdata/expected", 0o755)
+		_ = os.WriteFile("../testdata/expected/customers-fanout-plan-report.json", []byte(sb.String()), 0o644)
+	}
+}
+
+func TestRunSamplesNeedQueryLog(t *testing.T) {
+	cfg := Config{
+		VSchemaFile: "../testdata/planalyze-vschema-customers.json",
+		Samples:     10,
+	}
+
+	err := run(&strings.Builder{}, cfg, "../testdata/keys-output/keys-log-vtgate.json")
+	require.ErrorContains(t, err, "needs a query log")
+}
+
+func TestPlanComplexity(t *testing.T) {
+	route := func(opcode engine.Opcode) *engine.Route {
+		return &engine.Route{RoutingParameters: &engine.RoutingParameters{Opcode: opcode}}
+	}
+
+	assert.Equal(t, PassThrough, planComplexity(route(engine.EqualUnique)))
+	assert.Equal(t, PassThrough, planComplexity(route(engine.Reference)))
+	assert.Equal(t, SimpleRouted, planComplexity(route(engine.IN)))
+	assert.Equal(t, SimpleRouted, planComplexity(route(engine.MultiEqual)))
+	assert.Equal(t, Scatter, planComplexity(route(engine.Scatter)))
+	assert.Equal(t, Scatter, planComplexity(&engine.Delete{DML: &engine.DML{RoutingParameters: &engine.RoutingParameters{Opcode: engine.Scatter}}}))
+	assert.Equal(t, Complex, planComplexity(&engine.VindexLookup{Opcode: engine.EqualUnique, SendTo: route(engine.ByDestination)}))
+	assert.Equal(t, Complex, planComplexity(&engine.Join{Left: route(engine.EqualUnique), Right: route(engine.EqualUnique)}))
+	assert.Equal(t, Complex, planComplexity(&engine.MemorySort{Input: route(engine.Scatter)}))
+}
+
+func TestPlanComplexityJSON(t *testing.T) {
+	b, err := json.Marshal(Transition{From: Scatter, To: Complex})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"from": "Scatter", "to": "Complex routed", "queries": 0, "executions": 0, "queryTime": 0}`, string(b))
+
+	for _, c := range Complexities() {
+		b, err := json.Marshal(c)
+		require.NoError(t, err)
+		var read PlanComplexity
+		require.NoError(t, json.Unmarshal(b, &read))
+		assert.Equal(t, c, read)
+	}
+
+	// a comparison written before scatter routes had their own complexity
+	var q QueryComparison
+	require.NoError(t, json.Unmarshal([]byte(`{"queryStructure": "q", "before": 1, "after": 2}`), &q))
+	assert.Equal(t, SimpleRouted, q.Before)
+	assert.Equal(t, Complex, q.After)
+
+	var c PlanComplexity
+	require.Error(t, json.Unmarshal([]byte(`4`), &c))
+	require.Error(t, json.Unmarshal([]byte(`"Hard"`), &c))
+}
+
+func TestFromOutput(t *testing.T) {
+	// a file written before scatter routes had their own list numbers complex plans 2
+	var o Output
+	require.NoError(t, json.Unmarshal([]byte(`{"fileType": "planalyze", "simpleRouted": [{"QueryStructure": "q1", "Complexity": 1}],
+		"complex": [{"QueryStructure": "q2", "Complexity": 2}], "unplannable": [{"QueryStructure": "q3", "Complexity": 3}]}`), &o))
+
+	p := FromOutput(o)
+	assert.Equal(t, SimpleRouted, p.Queries[SimpleRouted][0].Complexity)
+	assert.Empty(t, p.Queries[Scatter])
+	assert.Equal(t, Complex, p.Queries[Complex][0].Complexity)
+	assert.Equal(t, Unplannable, p.Queries[Unplannable][0].Complexity)
+	assert.Equal(t, 1, p.Totals().Complex.Queries)
+}
@@ -0,0 +1,154 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package planalyze
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"vitess.io/vitess/go/vt/vtgate/engine"
+)
+
+type (
+	// Reason explains why a plan is more expensive than a single-shard route
+	Reason struct {
+		Kind ReasonKind
+		// Detail is the table, vindex or operator the reason applies to
+		Detail string
+	}
+
+	ReasonKind string
+)
+
+const (
+	ScatterRoute      ReasonKind = "ScatterRoute"
+	MultiShardRoute   ReasonKind = "MultiShardRoute"
+	ReferenceRoute    ReasonKind = "ReferenceRoute"
+	LookupVindex      ReasonKind = "LookupVindex"
+	CrossShardJoin    ReasonKind = "CrossShardJoin"
+	VTGateAggregation ReasonKind = "VTGateAggregation"
+	VTGateSort        ReasonKind = "VTGateSort"
+	SubqueryPullout   ReasonKind = "SubqueryPullout"
+)
+
+// ReasonKinds lists all the kinds, in the order they are reported
+var ReasonKinds = []ReasonKind{ //nolint:gochecknoglobals // this is instead of a const
+	ScatterRoute,
+	MultiShardRoute,
+	CrossShardJoin,
+	VTGateAggregation,
+	VTGateSort,
+	SubqueryPullout,
+	LookupVindex,
+	ReferenceRoute,
+}
+
+func (k ReasonKind) String() string {
+	switch k {
+	case ScatterRoute:
+		return "Scatter route"
+	case MultiShardRoute:
+		return "Targeted multi-shard route"
+	case ReferenceRoute:
+		return "Reference table route"
+	case LookupVindex:
+		return "Lookup vindex"
+	case CrossShardJoin:
+		return "Cross-shard join"
+	case VTGateAggregation:
+		return "Aggregation at vtgate"
+	case VTGateSort:
+		return "Sorting at vtgate"
+	case SubqueryPullout:
+		return "Subquery pullout"
+	}
+	return string(k)
+}
+
+func (r Reason) String() string {
+	if r.Detail == "" {
+		return r.Kind.String()
+	}
+	return fmt.Sprintf("%s: %s", r.Kind, r.Detail)
+}
+
+// planReasons walks the plan tree and collects the operations that make the plan more expensive,
+// in the order they appear in the plan
+func planReasons(plan engine.Primitive) []Reason {
+	var reasons []Reason
+	add := func(kind ReasonKind, detail string) {
+		r := Reason{Kind: kind, Detail: detail}
+		if !slices.Contains(reasons, r) {
+			reasons = append(reasons, r)
+		}
+	}
+
+	var walk func(p engine.Primitive)
+	walk = func(p engine.Primitive) {
+		switch prim := p.(type) {
+		case *engine.Route:
+			routeReasons(prim.RoutingParameters, prim.TableName, add)
+		case *engine.Update:
+			routeReasons(prim.RoutingParameters, strings.Join(prim.TableNames, ", "), add)
+		case *engine.Delete:
+			routeReasons(prim.RoutingParameters, strings.Join(prim.TableNames, ", "), add)
+		case *engine.VindexLookup:
+			add(LookupVindex, prim.Vindex.String())
+		case *engine.Join:
+			add(CrossShardJoin, prim.Opcode.String())
+		case *engine.HashJoin, *engine.BlockJoin:
+			add(CrossShardJoin, operatorName(p))
+		case *engine.OrderedAggregate, *engine.ScalarAggregate, *engine.Distinct:
+			add(VTGateAggregation, operatorName(p))
+		case *engine.MemorySort:
+			add(VTGateSort, "")
+		case *engine.UncorrelatedSubquery:
+			add(SubqueryPullout, prim.Opcode.String())
+		case *engine.SemiJoin:
+			add(SubqueryPullout, operatorName(p))
+		}
+
+		inputs, _ := p.Inputs()
+		for _, input := range inputs {
+			walk(input)
+		}
+	}
+	walk(plan)
+	return reasons
+}
+
+func routeReasons(rp *engine.RoutingParameters, tables string, add func(ReasonKind, string)) {
+	switch rp.Opcode {
+	case engine.Scatter:
+		add(ScatterRoute, tables)
+	case engine.Equal, engine.IN, engine.MultiEqual, engine.Between, engine.SubShard:
+		add(MultiShardRoute, fmt.Sprintf("%s (%s)", tables, rp.Opcode))
+	case engine.Reference:
+		add(ReferenceRoute, tables)
+	default:
+	}
+
+	if rp.Vindex != nil && rp.Vindex.NeedsVCursor() {
+		// vindexes that need a vcursor, like lookup vindexes, query another table before routing
+		add(LookupVindex, rp.Vindex.String())
+	}
+}
+
+func operatorName(p engine.Primitive) string {
+	return descriptionName(engine.PrimitiveToPlanDescription(p, nil))
+}
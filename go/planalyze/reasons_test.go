@@ -0,0 +1,53 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package planalyze
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"vitess.io/vitess/go/vt/vtgate/engine"
+)
+
+func TestPlanReasons(t *testing.T) {
+	route := func(opcode engine.Opcode, table string) *engine.Route {
+		return &engine.Route{RoutingParameters: &engine.RoutingParameters{Opcode: opcode}, TableName: table}
+	}
+
+	plan := &engine.MemorySort{
+		Input: &engine.Join{
+			Opcode: engine.LeftJoin,
+			Left:   route(engine.Scatter, "orders"),
+			Right: &engine.Join{
+				Opcode: engine.InnerJoin,
+				Left:   route(engine.IN, "customers"),
+				Right:  route(engine.Reference, "countries"),
+			},
+		},
+	}
+
+	assert.Equal(t, []Reason{
+		{Kind: VTGateSort},
+		{Kind: CrossShardJoin, Detail: "LeftJoin"},
+		{Kind: ScatterRoute, Detail: "orders"},
+		{Kind: CrossShardJoin, Detail: "Join"},
+		{Kind: MultiShardRoute, Detail: "customers (IN)"},
+		{Kind: ReferenceRoute, Detail: "countries"},
+	}, planReasons(plan))
+
+	assert.Empty(t, planReasons(route(engine.EqualUnique, "orders")))
+}
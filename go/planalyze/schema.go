@@ -0,0 +1,166 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package planalyze
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/vtenv"
+	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vtgate/semantics"
+	"vitess.io/vitess/go/vt/vtgate/vindexes"
+
+	"github.com/vitessio/vt/go/dbinfo"
+)
+
+// UnplannableCause tells apart queries Vitess cannot run from queries the planner lacked information for
+type UnplannableCause string
+
+const (
+	// UnsupportedByVitess is a query that uses a construct Vitess does not support
+	UnsupportedByVitess UnplannableCause = "UnsupportedByVitess"
+	// MissingSchemaInfo is a query that could not be planned because the column lists of the tables are unknown.
+	// These usually plan fine once a dbinfo file or a schema dump is supplied.
+	MissingSchemaInfo UnplannableCause = "MissingSchemaInfo"
+	// PlanningError is any other planning failure
+	PlanningError UnplannableCause = "PlanningError"
+)
+
+func (c UnplannableCause) String() string {
+	switch c {
+	case UnsupportedByVitess:
+		return "Unsupported by Vitess"
+	case MissingSchemaInfo:
+		return "Missing schema info"
+	case PlanningError:
+		return "Planning error"
+	}
+	return string(c)
+}
+
+func unplannableCause(err error) UnplannableCause {
+	var missing *semantics.ColumnsMissingInSchemaError
+	switch {
+	case vterrors.ErrState(err) == vterrors.BadFieldError, errors.As(err, &missing):
+		return MissingSchemaInfo
+	case vterrors.Code(err) == vtrpcpb.Code_UNIMPLEMENTED:
+		return UnsupportedByVitess
+	default:
+		return PlanningError
+	}
+}
+
+// tableColumns are the authoritative column lists of the tables, by table name
+type tableColumns map[string][]vindexes.Column
+
+func loadSchema(dbInfoFile, schemaFile string, env *vtenv.Environment) (tableColumns, error) {
+	switch {
+	case dbInfoFile != "" && schemaFile != "":
+		return nil, errors.New("specify at most one of the following flags: -dbinfo or -schema")
+	case dbInfoFile != "":
+		return loadDBInfoColumns(dbInfoFile)
+	case schemaFile != "":
+		return loadSchemaColumns(schemaFile, env.Parser())
+	default:
+		return nil, nil
+	}
+}
+
+func loadDBInfoColumns(fileName string) (tableColumns, error) {
+	info, err := dbinfo.Load(fileName)
+	if err != nil {
+		return nil, err
+	}
+
+	schemas := map[string]int{}
+	for _, table := range info.Tables {
+		schemas[strings.ToLower(table.Name)]++
+	}
+
+	tables := make(tableColumns)
+	for _, table := range info.Tables {
+		// the vschema tables are not qualified by schema, a name several schemas have could be any of them
+		if len(table.Columns) == 0 || schemas[strings.ToLower(table.Name)] > 1 {
+			continue
+		}
+		columns := make([]vindexes.Column, 0, len(table.Columns))
+		for _, col := range table.Columns {
+			columns = append(columns, col.VindexColumn())
+		}
+		tables[strings.ToLower(table.Name)] = columns
+	}
+	return tables, nil
+}
+
+// loadSchemaColumns reads the CREATE TABLE statements of an SQL schema dump, ignoring all other statements
+func loadSchemaColumns(fileName string, parser *sqlparser.Parser) (tableColumns, error) {
+	b, err := os.ReadFile(fileName)
+	if err != nil {
+		return nil, err
+	}
+
+	pieces, err := parser.SplitStatementToPieces(string(b))
+	if err != nil {
+		return nil, fmt.Errorf("error splitting %s: %w", fileName, err)
+	}
+
+	tables := make(tableColumns)
+	for _, piece := range pieces {
+		stmt, err := parser.Parse(piece)
+		if err != nil {
+			// dumps contain statements the parser does not know, like SET or LOCK TABLES with options
+			continue
+		}
+		create, ok := stmt.(*sqlparser.CreateTable)
+		if !ok || create.TableSpec == nil {
+			continue
+		}
+		columns := make([]vindexes.Column, 0, len(create.TableSpec.Columns))
+		for _, col := range create.TableSpec.Columns {
+			columns = append(columns, vindexes.Column{
+				Name:     col.Name,
+				Type:     col.Type.SQLType(),
+				Nullable: col.Type.Options == nil || col.Type.Options.Null == nil || *col.Type.Options.Null,
+			})
+		}
+		tables[strings.ToLower(create.Table.Name.String())] = columns
+	}
+	return tables, nil
+}
+
+// addColumns makes the column lists of the vschema tables authoritative, using the schema.
+// Tables that already have authoritative columns in the vschema are left alone.
+func addColumns(vschema *vindexes.VSchema, schema tableColumns) {
+	for _, ks := range vschema.Keyspaces {
+		for name, table := range ks.Tables {
+			if table.ColumnListAuthoritative {
+				continue
+			}
+			columns, ok := schema[strings.ToLower(name)]
+			if !ok {
+				continue
+			}
+			table.Columns = columns
+			table.ColumnListAuthoritative = true
+		}
+	}
+}
@@ -0,0 +1,96 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package planalyze
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"vitess.io/vitess/go/vt/vtenv"
+
+	"github.com/vitessio/vt/go/data"
+)
+
+func TestLoadSchema(t *testing.T) {
+	fromDBInfo, err := loadSchema("../testdata/dbInfo-output/tpch-dbinfo.json", "", vtenv.NewTestEnv())
+	require.NoError(t, err)
+	fromSQL, err := loadSchema("", "../testdata/tpch-schema.sql", vtenv.NewTestEnv())
+	require.NoError(t, err)
+
+	require.Len(t, fromSQL, 8)
+	require.Len(t, fromDBInfo, len(fromSQL))
+	for table, columns := range fromSQL {
+		require.Len(t, fromDBInfo[table], len(columns), table)
+		for i, col := range columns {
+			assert.True(t, col.Name.Equal(fromDBInfo[table][i].Name), "%s.%s", table, col.Name.String())
+			assert.Equal(t, col.Type, fromDBInfo[table][i].Type, "%s.%s", table, col.Name.String())
+		}
+	}
+
+	_, err = loadSchema("../testdata/dbInfo-output/tpch-dbinfo.json", "../testdata/tpch-schema.sql", vtenv.NewTestEnv())
+	require.Error(t, err)
+}
+
+func TestLoadDBInfoColumnsSeveralSchemas(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), "dbinfo.json")
+	err := os.WriteFile(fileName, []byte(`{
+  "fileType": "dbinfo",
+  "tables": [
+    {"name": "customers", "schema": "billing", "columns": [{"name": "id", "type": "bigint"}]},
+    {"name": "customers", "schema": "shop", "columns": [{"name": "id", "type": "bigint"}, {"name": "email", "type": "varchar"}]},
+    {"name": "orders", "schema": "shop", "columns": [{"name": "id", "type": "bigint"}]}
+  ]
+}`), 0o644)
+	require.NoError(t, err)
+
+	tables, err := loadDBInfoColumns(fileName)
+	require.NoError(t, err)
+	assert.Len(t, tables["orders"], 1)
+	assert.NotContains(t, tables, "customers")
+}
+
+func TestRunSchemaAuthority(t *testing.T) {
+	planTPCH := func(cfg Config) Output {
+		cfg.VSchemaFile = "../testdata/planalyze-vschema-tpch.json"
+		cfg.Loader = data.SlowQueryLogLoader{}
+
+		sb := &strings.Builder{}
+		require.NoError(t, run(sb, cfg, "../../t/tpch.test"))
+		var out Output
+		require.NoError(t, json.Unmarshal([]byte(sb.String()), &out))
+		return out
+	}
+
+	out := planTPCH(Config{})
+	causes := map[UnplannableCause]int{}
+	for _, q := range out.Unplannable {
+		causes[q.Cause]++
+	}
+	assert.Equal(t, 11, causes[MissingSchemaInfo])
+	assert.Equal(t, 1, causes[UnsupportedByVitess])
+
+	for _, cfg := range []Config{{DBInfoFile: "../testdata/dbInfo-output/tpch-dbinfo.json"}, {SchemaFile: "../testdata/tpch-schema.sql"}} {
+		out = planTPCH(cfg)
+		assert.Empty(t, out.Unplannable)
+		assert.Len(t, out.Complex, 17)
+	}
+}
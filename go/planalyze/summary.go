@@ -0,0 +1,124 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package planalyze
+
+import (
+	"slices"
+
+	"vitess.io/vitess/go/vt/vtgate/engine"
+)
+
+type (
+	// PlanSummary describes the shape of a plan, so plans can be read and compared without their JSON
+	PlanSummary struct {
+		// Operators counts the operators of the plan, in the order they first appear
+		Operators []OperatorCount
+
+		// Depth is the number of operators on the longest path from the root of the plan to a leaf
+		Depth int
+
+		// Routes counts the operators that send a query to the tablets, and ScatterRoutes the ones sending it to all shards
+		Routes        int
+		ScatterRoutes int
+
+		// JoinTypes lists the joins vtgate performs, like LeftJoin or HashJoin
+		JoinTypes []string `json:",omitempty"`
+
+		// MemorySort and MemoryAggregation are set when vtgate sorts or aggregates the rows itself
+		MemorySort        bool `json:",omitempty"`
+		MemoryAggregation bool `json:",omitempty"`
+	}
+
+	OperatorCount struct {
+		Operator string
+		Count    int
+
+		// Problematic operators make vtgate do work, or send the query to more than one shard
+		Problematic bool `json:",omitempty"`
+	}
+)
+
+// summarizePlan walks the description of a plan, the same one written as PlanOutput
+func summarizePlan(description engine.PrimitiveDescription) *PlanSummary {
+	summary := &PlanSummary{}
+	var walk func(d engine.PrimitiveDescription) int
+	walk = func(d engine.PrimitiveDescription) int {
+		summary.add(d)
+		depth := 0
+		for _, input := range d.Inputs {
+			depth = max(depth, walk(input))
+		}
+		return depth + 1
+	}
+	summary.Depth = walk(description)
+	return summary
+}
+
+func (s *PlanSummary) add(d engine.PrimitiveDescription) {
+	name := descriptionName(d)
+	idx := slices.IndexFunc(s.Operators, func(o OperatorCount) bool { return o.Operator == name })
+	if idx < 0 {
+		s.Operators = append(s.Operators, OperatorCount{Operator: name, Problematic: problematic(d)})
+		idx = len(s.Operators) - 1
+	}
+	s.Operators[idx].Count++
+
+	switch d.OperatorType {
+	case "Route", "Update", "Delete", "Insert", "Upsert":
+		s.Routes++
+		if d.Variant == engine.Scatter.String() {
+			s.ScatterRoutes++
+		}
+	case "Join":
+		if !slices.Contains(s.JoinTypes, d.Variant) {
+			s.JoinTypes = append(s.JoinTypes, d.Variant)
+		}
+	case "Sort":
+		s.MemorySort = s.MemorySort || d.Variant == "Memory"
+	case "Aggregate", "Distinct":
+		s.MemoryAggregation = true
+	}
+}
+
+// problematic matches the operators that planReasons reports, apart from reference routes which are cheap
+func problematic(d engine.PrimitiveDescription) bool {
+	switch d.OperatorType {
+	case "Route", "Update", "Delete":
+		switch d.Variant {
+		case engine.Scatter.String(), engine.Equal.String(), engine.IN.String(), engine.MultiEqual.String(),
+			engine.Between.String(), engine.SubShard.String():
+			return true
+		}
+	case "Join", "SemiJoin", "UncorrelatedSubquery", "Aggregate", "Distinct", "VindexLookup":
+		return true
+	case "Sort":
+		return d.Variant == "Memory"
+	}
+	return false
+}
+
+func descriptionName(d engine.PrimitiveDescription) string {
+	switch {
+	case d.OperatorType == "Join" && d.Variant != "":
+		// the variants of joins already name the operator, like LeftJoin or HashJoin
+		return d.Variant
+	case d.Variant == "":
+		return d.OperatorType
+	default:
+		return d.Variant + " " + d.OperatorType
+	}
+}
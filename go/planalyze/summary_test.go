@@ -0,0 +1,63 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package planalyze
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"vitess.io/vitess/go/vt/vtgate/engine"
+)
+
+func TestSummarizePlan(t *testing.T) {
+	route := func(opcode engine.Opcode, table string) *engine.Route {
+		return &engine.Route{RoutingParameters: &engine.RoutingParameters{Opcode: opcode}, TableName: table}
+	}
+
+	plan := &engine.MemorySort{
+		Input: &engine.Join{
+			Opcode: engine.LeftJoin,
+			Left:   route(engine.Scatter, "orders"),
+			Right: &engine.Join{
+				Opcode: engine.InnerJoin,
+				Left:   route(engine.Scatter, "customers"),
+				Right:  route(engine.Reference, "countries"),
+			},
+		},
+	}
+
+	assert.Equal(t, &PlanSummary{
+		Operators: []OperatorCount{
+			{Operator: "Memory Sort", Count: 1, Problematic: true},
+			{Operator: "LeftJoin", Count: 1, Problematic: true},
+			{Operator: "Scatter Route", Count: 2, Problematic: true},
+			{Operator: "Join", Count: 1, Problematic: true},
+			{Operator: "Reference Route", Count: 1},
+		},
+		Depth:         4,
+		Routes:        3,
+		ScatterRoutes: 2,
+		JoinTypes:     []string{"LeftJoin", "Join"},
+		MemorySort:    true,
+	}, summarizePlan(engine.PrimitiveToPlanDescription(plan, nil)))
+
+	assert.Equal(t, &PlanSummary{
+		Operators: []OperatorCount{{Operator: "EqualUnique Route", Count: 1}},
+		Depth:     1,
+		Routes:    1,
+	}, summarizePlan(engine.PrimitiveToPlanDescription(route(engine.EqualUnique, "orders"), nil)))
+}
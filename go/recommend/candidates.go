@@ -0,0 +1,190 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package recommend
+
+import (
+	"slices"
+	"sort"
+
+	"vitess.io/vitess/go/vt/sqlparser"
+
+	"github.com/vitessio/vt/go/dbinfo"
+	"github.com/vitessio/vt/go/keys"
+	"github.com/vitessio/vt/go/transactions"
+)
+
+type (
+	// tableCandidates are the choices the search tries for one table
+	tableCandidates struct {
+		table string
+		// rows is -1 when the row count is unknown
+		rows    int
+		columns []*columnUsage
+		// reference is true when the table is small enough to be copied to every shard
+		reference bool
+		// weight is the usage of all the columns of the table, used to try the busiest tables first
+		weight int
+	}
+
+	// columnUsage counts how a column is used, weighted by the usage count of the queries
+	columnUsage struct {
+		name       string
+		filters    int
+		joins      int
+		grouping   int
+		txs        int
+		primaryKey bool
+	}
+)
+
+func (c *columnUsage) weight() int {
+	return c.filters + c.joins + c.grouping + c.txs
+}
+
+func (tc *tableCandidates) choices() []Choice {
+	var choices []Choice
+	for _, col := range tc.columns {
+		choices = append(choices, Choice{Column: col.name})
+	}
+	if tc.reference {
+		choices = append(choices, Choice{Reference: true})
+	}
+	return choices
+}
+
+func (tc *tableCandidates) column(name string) *columnUsage {
+	for _, col := range tc.columns {
+		if col.name == name {
+			return col
+		}
+	}
+	return nil
+}
+
+// collectCandidates finds the sharding key candidates of every table: the columns used in equality filters,
+// join predicates, grouping and transactions, plus the primary key. Small tables can also be reference tables.
+func collectCandidates(queries []keys.QueryAnalysisResult, info *dbinfo.Info, edges []transactions.TableEdge, cfg Config) []*tableCandidates {
+	usage := map[string]map[string]*columnUsage{}
+	get := func(table, column string) *columnUsage {
+		cols := usage[table]
+		if cols == nil {
+			cols = map[string]*columnUsage{}
+			usage[table] = cols
+		}
+		col := cols[column]
+		if col == nil {
+			col = &columnUsage{name: column}
+			cols[column] = col
+		}
+		return col
+	}
+
+	for _, q := range queries {
+		w := max(q.UsageCount, 1)
+		for _, table := range q.TableNames {
+			if usage[table] == nil {
+				usage[table] = map[string]*columnUsage{}
+			}
+		}
+		for _, fc := range q.FilterColumns {
+			if fc.Uses == sqlparser.EqualOp || fc.Uses == sqlparser.InOp {
+				get(fc.Column.Table, fc.Column.Name).filters += w
+			}
+		}
+		for _, jp := range q.JoinPredicates {
+			if jp.Uses == sqlparser.EqualOp {
+				get(jp.LHS.Table, jp.LHS.Name).joins += w
+				get(jp.RHS.Table, jp.RHS.Name).joins += w
+			}
+		}
+		for _, gc := range q.GroupingColumns {
+			get(gc.Table, gc.Name).grouping += w
+		}
+	}
+
+	for _, edge := range edges {
+		for _, link := range edge.Columns {
+			get(edge.Table1, link.Col1).txs += link.Count
+			get(edge.Table2, link.Col2).txs += link.Count
+		}
+	}
+
+	rows := map[string]int{}
+	if info != nil {
+		schemas := map[string]int{}
+		for _, ti := range info.Tables {
+			schemas[ti.Name]++
+		}
+		for _, ti := range info.Tables {
+			// the vschema tables are not qualified by schema, a name several schemas have could be any of them
+			if schemas[ti.Name] > 1 {
+				continue
+			}
+			rows[ti.Name] = ti.Rows
+			if ti.PrimaryKey != nil && len(ti.PrimaryKey.Columns) == 1 {
+				get(ti.Name, ti.PrimaryKey.Columns[0]).primaryKey = true
+			} else if usage[ti.Name] == nil {
+				usage[ti.Name] = map[string]*columnUsage{}
+			}
+		}
+	}
+
+	var res []*tableCandidates
+	for table, cols := range usage {
+		tc := &tableCandidates{table: table, rows: -1}
+		if r, found := rows[table]; found {
+			tc.rows = r
+			tc.reference = r <= cfg.ReferenceRows
+		}
+		for _, col := range cols {
+			tc.columns = append(tc.columns, col)
+			tc.weight += col.weight()
+		}
+		sort.Slice(tc.columns, func(i, j int) bool {
+			a, b := tc.columns[i], tc.columns[j]
+			if a.weight() != b.weight() {
+				return a.weight() > b.weight()
+			}
+			if a.primaryKey != b.primaryKey {
+				return a.primaryKey
+			}
+			return a.name < b.name
+		})
+		tc.columns = keepCandidates(tc.columns, cfg.Candidates)
+		res = append(res, tc)
+	}
+
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].weight != res[j].weight {
+			return res[i].weight > res[j].weight
+		}
+		return res[i].table < res[j].table
+	})
+	return res
+}
+
+// keepCandidates keeps the n most used columns, and the primary key even if it is used less
+func keepCandidates(columns []*columnUsage, n int) []*columnUsage {
+	if len(columns) <= n {
+		return columns
+	}
+	pk := slices.IndexFunc(columns, func(c *columnUsage) bool { return c.primaryKey })
+	if pk >= n {
+		return append(columns[:n:n], columns[pk])
+	}
+	return columns[:n]
+}
@@ -0,0 +1,245 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package recommend
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	vschemapb "vitess.io/vitess/go/vt/proto/vschema"
+
+	"github.com/vitessio/vt/go/data"
+	"github.com/vitessio/vt/go/dbinfo"
+	"github.com/vitessio/vt/go/keys"
+	"github.com/vitessio/vt/go/planalyze"
+	"github.com/vitessio/vt/go/transactions"
+)
+
+type (
+	Config struct {
+		KeysFile         string
+		DBInfoFile       string
+		TransactionsFile string
+
+		// SchemaFile is an SQL schema dump. Like the dbinfo file, it gives the planner the column lists of the tables
+		SchemaFile string
+		// Planner selects the MySQL version and the vtgate settings the candidates are planned with
+		Planner data.PlannerOptions
+
+		// VSchemaOutput is a file the recommended vschema is written to,
+		// in a format that can be given to `vt planalyze --vschema`
+		VSchemaOutput string
+
+		// ReferenceRows is the maximum number of rows of a table that can be made a reference table.
+		// Row counts come from the dbinfo file, so no reference tables are suggested without it
+		ReferenceRows int
+		// Candidates is the number of sharding key candidates tried for each table
+		Candidates int
+	}
+
+	Output struct {
+		FileType string                `json:"fileType"`
+		VSchema  *vschemapb.SrvVSchema `json:"vschema"`
+
+		// Score is the planning cost of the workload with the recommended vschema, lower is better
+		Score  float64                    `json:"score"`
+		Totals planalyze.ComplexityTotals `json:"totals"`
+		Tables []TableRecommendation      `json:"tables"`
+
+		// Unresolved are the tables without any sharding key candidate. They are left out of the vschema
+		Unresolved []string `json:"unresolved,omitempty"`
+	}
+
+	TableRecommendation struct {
+		Table        string        `json:"table"`
+		Choice       Choice        `json:"choice"`
+		Reason       string        `json:"reason"`
+		Alternatives []Alternative `json:"alternatives,omitempty"`
+	}
+
+	// Choice is either a sharding column or a reference table
+	Choice struct {
+		Column    string `json:"column,omitempty"`
+		Reference bool   `json:"reference,omitempty"`
+	}
+
+	// Alternative is another choice for a table, scored with all other tables as recommended
+	Alternative struct {
+		Choice Choice  `json:"choice"`
+		Score  float64 `json:"score"`
+		// Better and Worse are the number of queries that get a simpler or a more complex plan than with the recommendation
+		Better int `json:"better"`
+		Worse  int `json:"worse"`
+	}
+)
+
+const (
+	DefaultReferenceRows = 1000
+	DefaultCandidates    = 3
+
+	keyspaceName = "main"
+	vindexName   = "xxhash"
+
+	// maxPasses bounds the search. Every pass tries all candidates of all tables
+	maxPasses = 3
+)
+
+// penalty is the cost of one execution of a query, by the complexity of its plan
+var penalty = [planalyze.Unplannable + 1]float64{ //nolint:gochecknoglobals // this is instead of a const
+	planalyze.PassThrough:  0,
+	planalyze.SimpleRouted: 1,
+	planalyze.Scatter:      2,
+	planalyze.Complex:      3,
+	planalyze.Unplannable:  5,
+}
+
+func (c Choice) String() string {
+	if c.Reference {
+		return "reference"
+	}
+	return c.Column
+}
+
+func Run(cfg Config) error {
+	return run(os.Stdout, cfg)
+}
+
+func run(out io.Writer, cfg Config) error {
+	if cfg.ReferenceRows < 0 || cfg.Candidates <= 0 {
+		return errors.New("--reference-rows must not be negative and --candidates must be positive")
+	}
+
+	ko, err := keys.ReadKeysFile(cfg.KeysFile)
+	if err != nil {
+		return err
+	}
+
+	var info *dbinfo.Info
+	if cfg.DBInfoFile != "" {
+		info, err = dbinfo.Load(cfg.DBInfoFile)
+		if err != nil {
+			return fmt.Errorf("error parsing dbinfo: %w", err)
+		}
+	}
+
+	var edges []transactions.TableEdge
+	if cfg.TransactionsFile != "" {
+		edges, err = readTableGraph(cfg.TransactionsFile)
+		if err != nil {
+			return err
+		}
+	}
+
+	// the candidates are planned like vt planalyze plans them, so queries do not fail for lack of column lists
+	pe, err := planalyze.NewPlanEnv(cfg.DBInfoFile, cfg.SchemaFile, cfg.Planner)
+	if err != nil {
+		return err
+	}
+
+	tables := collectCandidates(ko.Queries, info, edges, cfg)
+	s := newSearch(ko.Queries, tables, pe)
+	if err := s.run(); err != nil {
+		return err
+	}
+
+	res, err := s.output()
+	if err != nil {
+		return err
+	}
+
+	if cfg.VSchemaOutput != "" {
+		if err := writeVSchema(cfg.VSchemaOutput, res.VSchema); err != nil {
+			return err
+		}
+	}
+
+	jsonData, err := json.MarshalIndent(res, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = out.Write(jsonData)
+	return err
+}
+
+func readTableGraph(fileName string) ([]transactions.TableEdge, error) {
+	c, err := os.ReadFile(fileName)
+	if err != nil {
+		return nil, fmt.Errorf("error opening file: %w", err)
+	}
+
+	var to struct {
+		TableGraph []transactions.TableEdge `json:"table_graph"`
+	}
+	if err := json.Unmarshal(c, &to); err != nil {
+		return nil, fmt.Errorf("error parsing json: %w", err)
+	}
+	return to.TableGraph, nil
+}
+
+func writeVSchema(fileName string, vschema *vschemapb.SrvVSchema) error {
+	jsonData, err := json.MarshalIndent(vschema, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(fileName, jsonData, 0o600)
+}
+
+// buildVSchema creates a single sharded keyspace with the given choices
+func buildVSchema(choices map[string]Choice) *vschemapb.SrvVSchema {
+	ks := &vschemapb.Keyspace{
+		Sharded: true,
+		Vindexes: map[string]*vschemapb.Vindex{
+			vindexName: {Type: vindexName},
+		},
+		Tables: map[string]*vschemapb.Table{},
+	}
+	for table, choice := range choices {
+		if choice.Reference {
+			ks.Tables[table] = &vschemapb.Table{Type: "reference"}
+			continue
+		}
+		ks.Tables[table] = &vschemapb.Table{
+			ColumnVindexes: []*vschemapb.ColumnVindex{{Column: choice.Column, Name: vindexName}},
+		}
+	}
+	return &vschemapb.SrvVSchema{Keyspaces: map[string]*vschemapb.Keyspace{keyspaceName: ks}}
+}
+
+// score sums the penalty of every execution of the queries
+func score(p *planalyze.Planalyze) float64 {
+	var total float64
+	for complexity, queries := range p.Queries {
+		for _, q := range queries {
+			total += penalty[complexity] * float64(max(q.UsageCount, 1))
+		}
+	}
+	return total
+}
+
+func choicesKey(choices map[string]Choice) string {
+	parts := make([]string, 0, len(choices))
+	for table, choice := range choices {
+		parts = append(parts, table+"="+choice.String())
+	}
+	sort.Strings(parts)
+	return strings.Join(parts, ";")
+}
@@ -0,0 +1,130 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package recommend
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/vitessio/vt/go/data"
+	"github.com/vitessio/vt/go/dbinfo"
+	"github.com/vitessio/vt/go/keys"
+	"github.com/vitessio/vt/go/planalyze"
+)
+
+func TestRun(t *testing.T) {
+	sb := &strings.Builder{}
+	vschemaFile := filepath.Join(t.TempDir(), "vschema.json")
+	cfg := Config{
+		KeysFile:      "../testdata/keys-output/keys-log-vtgate.json",
+		DBInfoFile:    "../testdata/dbInfo-output/customers-dbinfo.json",
+		VSchemaOutput: vschemaFile,
+		ReferenceRows: DefaultReferenceRows,
+		Candidates:    DefaultCandidates,
+	}
+
+	err := run(sb, cfg)
+	require.NoError(t, err)
+
+	out, err := os.ReadFile("../testdata/recommend-output/keys-log-vtgate-recommendation.json")
+	require.NoError(t, err)
+
+	assert.Equal(t, string(out), sb.String())
+	if t.Failed() {
+		_ = os.Mkdir("../testdata/expected", 0o755)
+		_ = os.WriteFile("../testdata/expected/keys-log-vtgate-recommendation.json", []byte(sb.String()), 0o644)
+	}
+
+	// the recommended vschema can be used as input to planalyze
+	_, vschema, err := data.ReadVschema(vschemaFile, false)
+	require.NoError(t, err)
+	ks := vschema.Keyspaces["main"]
+	require.NotNil(t, ks)
+	assert.True(t, ks.Keyspace.Sharded)
+	assert.Len(t, ks.Tables, 3)
+}
+
+func TestKeepCandidates(t *testing.T) {
+	columns := []*columnUsage{
+		{name: "a", filters: 10},
+		{name: "b", joins: 5},
+		{name: "c", txs: 2},
+		{name: "id", primaryKey: true},
+	}
+
+	names := func(cols []*columnUsage) []string {
+		var res []string
+		for _, col := range cols {
+			res = append(res, col.name)
+		}
+		return res
+	}
+	assert.Equal(t, []string{"a", "b", "id"}, names(keepCandidates(columns, 2)))
+	assert.Equal(t, []string{"a", "b", "c", "id"}, names(keepCandidates(columns, 4)))
+	assert.Equal(t, []string{"id", "a"}, names(keepCandidates([]*columnUsage{columns[3], columns[0], columns[1]}, 2)))
+}
+
+func TestCollectCandidatesSeveralSchemas(t *testing.T) {
+	info := &dbinfo.Info{Tables: []*dbinfo.TableInfo{
+		{Name: "customers", Schema: "billing", Rows: 10, PrimaryKey: &dbinfo.PrimaryKey{Columns: []string{"id"}}},
+		{Name: "customers", Schema: "shop", Rows: 50000, PrimaryKey: &dbinfo.PrimaryKey{Columns: []string{"customer_id"}}},
+		{Name: "orders", Schema: "shop", Rows: 80000, PrimaryKey: &dbinfo.PrimaryKey{Columns: []string{"id"}}},
+	}}
+
+	tables := map[string]*tableCandidates{}
+	for _, tc := range collectCandidates(nil, info, nil, Config{ReferenceRows: 100, Candidates: 3}) {
+		tables[tc.table] = tc
+	}
+
+	// only shop has an orders table
+	require.Contains(t, tables, "orders")
+	assert.Equal(t, 80000, tables["orders"].rows)
+	require.Len(t, tables["orders"].columns, 1)
+	assert.True(t, tables["orders"].columns[0].primaryKey)
+
+	// both schemas have a customers table, neither row count nor primary key is attributed to it
+	assert.NotContains(t, tables, "customers")
+}
+
+func TestSearchPlansWithSchema(t *testing.T) {
+	// the unqualified columns of the cross-shard join can only be resolved with the column lists of the tables
+	queries := []keys.QueryAnalysisResult{{
+		QueryStructure: "select c_name, o_totalprice from customer join orders on c_nationkey = o_orderkey",
+		UsageCount:     1,
+	}}
+	choices := map[string]Choice{"customer": {Column: "c_custkey"}, "orders": {Column: "o_custkey"}}
+
+	evaluate := func(schemaFile string) *planalyze.Planalyze {
+		pe, err := planalyze.NewPlanEnv("", schemaFile, data.PlannerOptions{})
+		require.NoError(t, err)
+		e, err := newSearch(queries, nil, pe).evaluate(choices)
+		require.NoError(t, err)
+		return e.result
+	}
+
+	without := evaluate("")
+	require.Len(t, without.Queries[planalyze.Unplannable], 1)
+	assert.Equal(t, planalyze.MissingSchemaInfo, without.Queries[planalyze.Unplannable][0].Cause)
+
+	with := evaluate("../testdata/tpch-schema.sql")
+	assert.Empty(t, with.Queries[planalyze.Unplannable])
+}
@@ -0,0 +1,187 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package recommend
+
+import (
+	"fmt"
+	"maps"
+	"sort"
+
+	"github.com/dustin/go-humanize"
+
+	"github.com/vitessio/vt/go/keys"
+	"github.com/vitessio/vt/go/planalyze"
+)
+
+type (
+	// search looks for the vschema with the lowest score.
+	// It starts with the most used column of every table, and then changes one table at a time,
+	// keeping a change when it lowers the score, until a whole pass over the tables changes nothing.
+	search struct {
+		queries []keys.QueryAnalysisResult
+		tables  []*tableCandidates
+		// pe is the schema and the planner settings every candidate is planned with
+		pe *planalyze.PlanEnv
+
+		choices map[string]Choice
+		best    *evaluation
+
+		// cache holds the evaluated vschemas, keyed by choicesKey
+		cache map[string]*evaluation
+	}
+
+	evaluation struct {
+		result *planalyze.Planalyze
+		score  float64
+	}
+)
+
+func newSearch(queries []keys.QueryAnalysisResult, tables []*tableCandidates, pe *planalyze.PlanEnv) *search {
+	return &search{
+		queries: queries,
+		tables:  tables,
+		pe:      pe,
+		choices: map[string]Choice{},
+		cache:   map[string]*evaluation{},
+	}
+}
+
+func (s *search) evaluate(choices map[string]Choice) (*evaluation, error) {
+	key := choicesKey(choices)
+	if e, found := s.cache[key]; found {
+		return e, nil
+	}
+
+	result, err := planalyze.Analyze(buildVSchema(choices), s.queries, s.pe)
+	if err != nil {
+		return nil, err
+	}
+	e := &evaluation{result: result, score: score(result)}
+	s.cache[key] = e
+	return e, nil
+}
+
+// with returns the current choices, with one table changed
+func (s *search) with(table string, choice Choice) map[string]Choice {
+	choices := maps.Clone(s.choices)
+	choices[table] = choice
+	return choices
+}
+
+func (s *search) run() error {
+	for _, tc := range s.tables {
+		if choices := tc.choices(); len(choices) > 0 {
+			s.choices[tc.table] = choices[0]
+		}
+	}
+
+	best, err := s.evaluate(s.choices)
+	if err != nil {
+		return err
+	}
+
+	for range maxPasses {
+		improved := false
+		for _, tc := range s.tables {
+			for _, choice := range tc.choices() {
+				if choice == s.choices[tc.table] {
+					continue
+				}
+				e, err := s.evaluate(s.with(tc.table, choice))
+				if err != nil {
+					return err
+				}
+				if e.score < best.score {
+					best = e
+					s.choices[tc.table] = choice
+					improved = true
+				}
+			}
+		}
+		if !improved {
+			break
+		}
+	}
+
+	s.best = best
+	return nil
+}
+
+func (s *search) output() (Output, error) {
+	res := Output{
+		FileType: "recommend",
+		VSchema:  buildVSchema(s.choices),
+		Score:    s.best.score,
+		Totals:   s.best.result.Totals(),
+	}
+
+	for _, tc := range s.tables {
+		choice, found := s.choices[tc.table]
+		if !found {
+			res.Unresolved = append(res.Unresolved, tc.table)
+			continue
+		}
+
+		rec := TableRecommendation{
+			Table:  tc.table,
+			Choice: choice,
+			Reason: reason(tc, choice),
+		}
+		for _, alt := range tc.choices() {
+			if alt == choice {
+				continue
+			}
+			e, err := s.evaluate(s.with(tc.table, alt))
+			if err != nil {
+				return res, err
+			}
+			rec.Alternatives = append(rec.Alternatives, newAlternative(alt, s.best, e))
+		}
+		sort.SliceStable(rec.Alternatives, func(i, j int) bool {
+			return rec.Alternatives[i].Score < rec.Alternatives[j].Score
+		})
+		res.Tables = append(res.Tables, rec)
+	}
+	sort.Strings(res.Unresolved)
+	return res, nil
+}
+
+func newAlternative(choice Choice, best, e *evaluation) Alternative {
+	alt := Alternative{Choice: choice, Score: e.score}
+	for _, q := range planalyze.Compare(best.result, e.result).Changed {
+		if q.After < q.Before {
+			alt.Better++
+		} else {
+			alt.Worse++
+		}
+	}
+	return alt
+}
+
+func reason(tc *tableCandidates, choice Choice) string {
+	if choice.Reference {
+		return fmt.Sprintf("%s rows, small enough to be copied to every shard", humanize.Comma(int64(tc.rows)))
+	}
+
+	col := tc.column(choice.Column)
+	r := fmt.Sprintf("sharded by %s, used by %d equality filters, %d joins, %d groupings and %d transactions (weighted by usage)",
+		col.name, col.filters, col.joins, col.grouping, col.txs)
+	if col.primaryKey {
+		r += "; primary key"
+	}
+	return r
+}
@@ -0,0 +1,118 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package summarize
+
+import (
+	"fmt"
+	"strings"
+
+	"vitess.io/vitess/go/vt/sqlparser"
+
+	"github.com/vitessio/vt/go/dbinfo"
+	"github.com/vitessio/vt/go/markdown"
+)
+
+// CompatibilityBlocker is a database object from a dbinfo file that Vitess does not handle
+// the way MySQL does, and that has to be looked at before sharding
+type CompatibilityBlocker struct {
+	Type    string
+	Name    string
+	Details string
+	Issue   string
+}
+
+const (
+	procedureIssue = "Procedures can only be called in unsharded keyspaces or when targeting a single shard"
+	functionIssue  = "Functions run on each shard and only see the rows of that shard"
+	triggerIssue   = "Triggers run on each shard and during VReplication copies, they must only change rows of the same shard"
+	eventIssue     = "Events run on the primary of every shard and are not moved by VReplication"
+	viewIssue      = "Views need vtgate `--enable-views` and a definition Vitess can plan in the keyspace"
+)
+
+func qualifiedName(schema, name string) string {
+	if schema == "" {
+		return name
+	}
+	return schema + "." + name
+}
+
+// compatibilityBlockers lists the routines, triggers, events and views of a dbinfo file
+func compatibilityBlockers(info *dbinfo.Info) []CompatibilityBlocker {
+	var blockers []CompatibilityBlocker
+	for _, r := range info.Routines {
+		b := CompatibilityBlocker{Type: "Procedure", Name: qualifiedName(r.Schema, r.Name), Details: r.DataAccess, Issue: procedureIssue}
+		if strings.EqualFold(r.Type, "FUNCTION") {
+			b.Type = "Function"
+			b.Issue = functionIssue
+		}
+		blockers = append(blockers, b)
+	}
+	for _, t := range info.Triggers {
+		blockers = append(blockers, CompatibilityBlocker{
+			Type:    "Trigger",
+			Name:    qualifiedName(t.Schema, t.Name),
+			Details: fmt.Sprintf("%s %s on `%s`", t.Timing, t.Event, qualifiedName(t.Schema, t.Table)),
+			Issue:   triggerIssue,
+		})
+	}
+	for _, e := range info.Events {
+		blockers = append(blockers, CompatibilityBlocker{
+			Type:    "Event",
+			Name:    qualifiedName(e.Schema, e.Name),
+			Details: fmt.Sprintf("%s, %s", e.Schedule, e.Status),
+			Issue:   eventIssue,
+		})
+	}
+	parser := sqlparser.NewTestParser()
+	for _, v := range info.Views {
+		blockers = append(blockers, viewBlocker(parser, v))
+	}
+	return blockers
+}
+
+func viewBlocker(parser *sqlparser.Parser, v *dbinfo.View) CompatibilityBlocker {
+	b := CompatibilityBlocker{Type: "View", Name: qualifiedName(v.Schema, v.Name), Issue: viewIssue}
+	stmt, err := parser.Parse(v.Definition)
+	if err != nil {
+		b.Issue = "Vitess cannot parse the view definition: " + err.Error()
+		return b
+	}
+	var details []string
+	if v.Updatable {
+		details = append(details, "updatable")
+	}
+	if tables := sqlparser.ExtractAllTables(stmt); len(tables) > 0 {
+		details = append(details, "reads "+strings.Join(tables, ", "))
+	}
+	b.Details = strings.Join(details, ", ")
+	return b
+}
+
+func renderCompatibilityBlockers(md *markdown.MarkDown, blockers []CompatibilityBlocker) {
+	if len(blockers) == 0 {
+		return
+	}
+
+	md.PrintHeader("Vitess Compatibility Blockers", 2)
+	md.Printf("Stored routines, triggers, events and views need to be reviewed, and usually moved to the application, before sharding.\n\n")
+	headers := []string{"Type", "Name", "Details", "Issue"}
+	rows := make([][]string, 0, len(blockers))
+	for _, b := range blockers {
+		rows = append(rows, []string{b.Type, b.Name, b.Details, b.Issue})
+	}
+	md.PrintTable(headers, rows)
+}
@@ -24,6 +24,8 @@ import (
 	"html/template"
 	"net"
 	"net/http"
+
+	"github.com/vitessio/vt/go/transactions"
 )
 
 type (
@@ -51,6 +53,8 @@ type (
 	forceGraphData struct {
 		maxValue   int
 		maxNumRows int
+		// maxTxValue is the highest transaction count of a transaction link, used to filter links in the view
+		maxTxValue int
 		graphData
 	}
 )
@@ -80,6 +84,9 @@ func createForceGraphData(s *Summary) forceGraphData {
 		if l.Value > result.maxValue {
 			result.maxValue = l.Value
 		}
+		if l.Type == "tx" && l.Value > result.maxTxValue {
+			result.maxTxValue = l.Value
+		}
 		m[createGraphKey(l.Source, l.Target)] = append(m[createGraphKey(l.Source, l.Target)], i)
 	}
 	const curvatureMinMax = 0.5
@@ -118,6 +125,10 @@ func addForeignKeys(s *Summary, result *forceGraphData, idxTableNode map[string]
 }
 
 func addTransactions(s *Summary, result *forceGraphData, idxTableNode map[string]int) {
+	if len(s.TxGraph) > 0 {
+		addTableGraph(s, result, idxTableNode)
+		return
+	}
 	txTablesMap := make(map[graphKey]int)
 	for _, transaction := range s.Transactions {
 		var tables []string
@@ -147,6 +158,30 @@ func addTransactions(s *Summary, result *forceGraphData, idxTableNode map[string
 	}
 }
 
+// addTableGraph adds the co-modification graph produced by `vt transactions`,
+// where the value of a link is the number of transactions that modify both tables
+func addTableGraph(s *Summary, result *forceGraphData, idxTableNode map[string]int) {
+	for _, edge := range s.TxGraph {
+		var cols []string
+		for _, col := range edge.Columns {
+			cols = append(cols, columnLinkString(edge, col))
+		}
+		result.Links = append(result.Links, link{
+			Source:     edge.Table1,
+			SourceIdx:  idxTableNode[edge.Table1],
+			Target:     edge.Table2,
+			TargetIdx:  idxTableNode[edge.Table2],
+			Value:      edge.Count,
+			Type:       "tx",
+			Predicates: cols,
+		})
+	}
+}
+
+func columnLinkString(edge transactions.TableEdge, col transactions.ColumnLink) string {
+	return fmt.Sprintf("%s.%s = %s.%s (%d)", edge.Table1, col.Col1, edge.Table2, col.Col2, col.Count)
+}
+
 func addJoins(s *Summary, result *forceGraphData, idxTableNode map[string]int) {
 	for _, join := range s.Joins {
 		var preds []string
@@ -218,11 +253,13 @@ func serveIndex(w http.ResponseWriter, data forceGraphData) error {
 		Data       any
 		MaxValue   int
 		MaxNumRows int
+		MaxTxValue int
 	}{
 		// nolint: gosec,nolintlint // this is all ran locally so no need to care about vulnerabilities around escaping
 		Data:       template.JS(dataBytes),
 		MaxValue:   data.maxValue,
 		MaxNumRows: data.maxNumRows,
+		MaxTxValue: data.maxTxValue,
 	}
 
 	if err := tmpl.Execute(w, d); err != nil {
@@ -20,16 +20,20 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"maps"
 	"slices"
 	"sort"
 	"strconv"
 	"strings"
 
 	humanize "github.com/dustin/go-humanize"
+	"vitess.io/vitess/go/slice"
 
+	"github.com/vitessio/vt/go/dbinfo"
 	"github.com/vitessio/vt/go/keys"
 	"github.com/vitessio/vt/go/markdown"
 	"github.com/vitessio/vt/go/planalyze"
+	"github.com/vitessio/vt/go/transactions"
 )
 
 func renderHotQueries(md *markdown.MarkDown, queries []keys.QueryAnalysisResult, metricReader getMetric) {
@@ -134,23 +138,140 @@ func renderTableOverview(md *markdown.MarkDown, tableSummaries []*TableSummary,
 func renderColumnUsageTable(md *markdown.MarkDown, summary *TableSummary) {
 	md.PrintHeader(fmt.Sprintf("Table: `%s` (%d reads and %d writes)", summary.Table, summary.ReadQueryCount, summary.WriteQueryCount), 4)
 
+	// with the column definitions of a dbinfo file, the types are shown and poor sharding keys are flagged
+	withSchema := len(summary.Columns) > 0
 	headers := []string{"Column", "Position", "Used %"}
+	if withSchema {
+		headers = append(headers, "Type", "Sharding Key Warnings")
+	}
 	var rows [][]string
 	var lastName string
 	for colInfo, usage := range summary.GetColumns() {
 		name := colInfo.Name
-		if lastName == name {
-			name = ""
-		} else {
+		first := lastName != name
+		if first {
 			lastName = name
+		} else {
+			name = ""
 		}
-		rows = append(rows, []string{
+		row := []string{
 			name,
 			colInfo.Pos.String(),
 			fmt.Sprintf("%.0f%%", usage.Percentage),
+		}
+		if withSchema {
+			var typ, warnings string
+			if first {
+				typ, warnings = summary.columnDefinition(colInfo.Name)
+			}
+			row = append(row, typ, warnings)
+		}
+		rows = append(rows, row)
+	}
+
+	md.PrintTable(headers, rows)
+}
+
+// columnDefinition returns the type of a column, and why it makes a poor sharding key
+// when it is used in joins or equality filters, the way sharding keys are used
+func (ts TableSummary) columnDefinition(name string) (typ, warnings string) {
+	idx := slices.IndexFunc(ts.Columns, func(c *dbinfo.TableColumn) bool { return strings.EqualFold(c.Name, name) })
+	if idx < 0 {
+		return "", ""
+	}
+	col := ts.Columns[idx]
+	typ = col.ColumnType
+	if typ == "" {
+		typ = col.Type
+	}
+
+	_, join := ts.ColumnUses[(&ColumnInformation{Name: name, Pos: Join}).String()]
+	_, where := ts.ColumnUses[(&ColumnInformation{Name: name, Pos: Where}).String()]
+	if !join && !where {
+		return typ, ""
+	}
+	return typ, strings.Join(col.ShardingKeyIssues(), ", ")
+}
+
+// renderTableSizes lists the tables with size information from a dbinfo file, largest first,
+// with the number of shards each would need to stay under the target shard size
+func renderTableSizes(md *markdown.MarkDown, tables []*TableSummary, targetShardSize uint64) {
+	var sized []*TableSummary
+	for _, table := range tables {
+		if table.Size.Total() > 0 {
+			sized = append(sized, table)
+		}
+	}
+	if len(sized) == 0 {
+		return
+	}
+	if targetShardSize == 0 {
+		targetShardSize = DefaultTargetShardSize
+	}
+
+	sort.Slice(sized, func(i, j int) bool {
+		if sized[i].Size.Total() == sized[j].Size.Total() {
+			return sized[i].Table < sized[j].Table
+		}
+		return sized[i].Size.Total() > sized[j].Size.Total()
+	})
+
+	md.PrintHeader("Table Sizes", 2)
+	md.Printf("Estimated shards are the data and index size divided by a target shard size of %s.\n\n", humanize.Bytes(targetShardSize))
+	headers := []string{"Table Name", "Engine", "Rows", "Avg Row Length", "Data Size", "Index Size", "Auto Increment", "Estimated Shards"}
+	var rows [][]string
+	var total dbinfo.TableSize
+	var totalRows int
+	for _, table := range sized {
+		size := table.Size
+		autoIncrement := ""
+		if size.AutoIncrement > 0 {
+			autoIncrement = humanize.Comma(int64(size.AutoIncrement))
+		}
+		name := table.Table
+		if table.Schema != "" && !strings.Contains(name, ".") {
+			name = table.Schema + "." + name
+		}
+		rows = append(rows, []string{
+			name,
+			size.Engine,
+			humanize.Comma(int64(table.RowCount)),
+			humanize.Bytes(uint64(size.AvgRowLength)),
+			humanize.Bytes(uint64(size.DataLength)),
+			humanize.Bytes(uint64(size.IndexLength)),
+			autoIncrement,
+			strconv.Itoa(estimatedShards(size.Total(), targetShardSize)),
 		})
+		total.DataLength += size.DataLength
+		total.IndexLength += size.IndexLength
+		totalRows += table.RowCount
 	}
+	rows = append(rows, []string{
+		"Total", "", humanize.Comma(int64(totalRows)), "",
+		humanize.Bytes(uint64(total.DataLength)), humanize.Bytes(uint64(total.IndexLength)), "",
+		strconv.Itoa(estimatedShards(total.Total(), targetShardSize)),
+	})
+	md.PrintTable(headers, rows)
+	md.NewLine()
+}
+
+func estimatedShards(size int64, targetShardSize uint64) int {
+	shards := (uint64(size) + targetShardSize - 1) / targetShardSize
+	return int(max(shards, 1))
+}
 
+func renderAmbiguousTables(md *markdown.MarkDown, ambiguous map[string][]string) {
+	if len(ambiguous) == 0 {
+		return
+	}
+
+	md.PrintHeader("Ambiguous Tables", 2)
+	md.Printf("These tables are used without a schema, and several schemas of the dbinfo file have them. Their usage is not attributed to any of them.\n\n")
+	headers := []string{"Table Name", "Schemas"}
+	var rows [][]string
+	for _, name := range slices.Sorted(maps.Keys(ambiguous)) {
+		rows = append(rows, []string{name, strings.Join(ambiguous[name], ", ")})
+	}
 	md.PrintTable(headers, rows)
 }
 
@@ -208,12 +329,32 @@ func renderTransactions(md *markdown.MarkDown, transactions []TransactionSummary
 		}
 		tables = uniquefy(tables)
 		md.NewLine()
-		md.PrintHeader(fmt.Sprintf("Pattern %d (Observed %d times)\n\n", i+1, tx.Count), 3)
+		if len(tx.Variants) > 0 {
+			md.PrintHeader(fmt.Sprintf("Pattern %d (Observed %d times, %d variants)\n\n", i+1, tx.Count, len(tx.Variants)), 3)
+		} else {
+			md.PrintHeader(fmt.Sprintf("Pattern %d (Observed %d times)\n\n", i+1, tx.Count), 3)
+		}
 		md.Printf("Tables Involved: %s\n", strings.Join(tables, ", "))
+		renderTransactionStats(md, tx)
 		md.PrintHeader("Query Patterns", 3)
 		for i, query := range tx.Queries {
-			md.Printf("%d. **%s** on `%s`  \n", i+1, strings.ToTitle(query.Type), query.Table)
-			md.Printf("   Predicates: %s\n\n", strings.Join(query.Predicates, " AND "))
+			md.Printf("%d. **%s** on `%s`%s  \n", i+1, strings.ToTitle(query.Type), query.Table, repeatSuffix(query))
+			if len(query.Assignments) > 0 {
+				md.Printf("   Assignments: %s  \n", strings.Join(query.Assignments, ", "))
+			}
+			if len(query.Predicates) > 0 || len(query.Assignments) == 0 {
+				md.Printf("   Predicates: %s\n\n", strings.Join(query.Predicates, " AND "))
+			} else {
+				md.NewLine()
+			}
+		}
+
+		if len(tx.Variants) > 0 {
+			md.PrintHeader("Variants", 3)
+			for _, variant := range tx.Variants {
+				md.Printf("* %s\n", variant)
+			}
+			md.NewLine()
 		}
 
 		md.PrintHeader("Shared Predicate Values", 3)
@@ -229,38 +370,369 @@ func renderTransactions(md *markdown.MarkDown, transactions []TransactionSummary
 	}
 }
 
+// repeatSuffix shows how many times a query is run in a row, when repeated statements were collapsed
+func repeatSuffix(query QueryPattern) string {
+	switch {
+	case query.MaxRepeat > max(query.Repeat, 1):
+		return fmt.Sprintf(" (x%d-%d)", max(query.Repeat, 1), query.MaxRepeat)
+	case query.Repeat > 1:
+		return fmt.Sprintf(" (x%d)", query.Repeat)
+	default:
+		return ""
+	}
+}
+
+func renderTransactionStats(md *markdown.MarkDown, tx TransactionSummary) {
+	if tx.Stats == nil {
+		return
+	}
+	md.PrintHeader("Statistics", 3)
+	headers := []string{"Metric", "Avg", "Max", "Total"}
+	rows := [][]string{
+		{"Duration (ms)", avgMillis(tx.Stats.TotalDuration, tx.Count), millis(tx.Stats.MaxDuration), millis(tx.Stats.TotalDuration)},
+		{"Idle in transaction (ms)", avgMillis(tx.Stats.TotalIdleTime, tx.Count), millis(tx.Stats.MaxIdleTime), millis(tx.Stats.TotalIdleTime)},
+		{"Lock time (ms)", avgMillis(tx.Stats.TotalLockTime, tx.Count), millis(tx.Stats.MaxLockTime), millis(tx.Stats.TotalLockTime)},
+	}
+	md.PrintTable(headers, rows)
+
+	statementCounts := slices.Sorted(maps.Keys(tx.Stats.StatementCounts))
+	var dist []string
+	for _, statements := range statementCounts {
+		dist = append(dist, fmt.Sprintf("%d (%d times)", statements, tx.Stats.StatementCounts[statements]))
+	}
+	md.Printf("Statements per transaction: %s\n\n", strings.Join(dist, ", "))
+}
+
+func renderTablesModifiedTogether(md *markdown.MarkDown, edges []transactions.TableEdge) {
+	if len(edges) == 0 {
+		return
+	}
+
+	md.PrintHeader("Tables Modified Together", 2)
+	headers := []string{"Tables", "Transactions", "Shared Values"}
+	var rows [][]string
+	for _, edge := range edges {
+		rows = append(rows, []string{
+			fmt.Sprintf("%s, %s", edge.Table1, edge.Table2),
+			humanize.Comma(int64(edge.Count)),
+			strings.Join(slice.Map(edge.Columns, func(c transactions.ColumnLink) string {
+				return columnLinkString(edge, c)
+			}), ", "),
+		})
+	}
+	md.PrintTable(headers, rows)
+}
+
+func renderAutocommit(md *markdown.MarkDown, report *transactions.AutocommitReport) {
+	if report == nil {
+		return
+	}
+
+	md.PrintHeader("Autocommit", 2)
+	defaultMode := "off"
+	if report.Default {
+		defaultMode = "on"
+	}
+	how := "configured"
+	if report.Mode == transactions.AutocommitAuto {
+		how = "guessed from the log"
+	}
+	md.Printf("Connections start with autocommit %s (%s).\n\n", defaultMode, how)
+	headers := []string{"Autocommit", "Connections"}
+	rows := [][]string{
+		{"On", humanize.Comma(int64(report.On))},
+		{"Off", humanize.Comma(int64(report.Off))},
+		{"Mixed", humanize.Comma(int64(report.Mixed))},
+	}
+	md.PrintTable(headers, rows)
+}
+
+func renderLongestTransactions(md *markdown.MarkDown, txs []TransactionSummary) {
+	if len(txs) == 0 {
+		return
+	}
+
+	md.PrintHeader("Longest Transactions", 2)
+	headers := []string{"Tables", "Count", "Avg Duration (ms)", "Max Duration (ms)", "Avg Idle (ms)", "Total Lock Time (ms)", "Avg Statements"}
+	var rows [][]string
+	for _, tx := range txs {
+		var tables []string
+		for _, query := range tx.Queries {
+			tables = append(tables, query.Table)
+		}
+		rows = append(rows, []string{
+			strings.Join(uniquefy(tables), ", "),
+			humanize.Comma(int64(tx.Count)),
+			avgMillis(tx.Stats.TotalDuration, tx.Count),
+			millis(tx.Stats.MaxDuration),
+			avgMillis(tx.Stats.TotalIdleTime, tx.Count),
+			millis(tx.Stats.TotalLockTime),
+			fmt.Sprintf("%.1f", tx.Stats.AvgStatements()),
+		})
+	}
+	md.PrintTable(headers, rows)
+}
+
+func millis(seconds float64) string {
+	return fmt.Sprintf("%.2f", seconds*1000)
+}
+
+func avgMillis(seconds float64, count int) string {
+	if count == 0 {
+		return millis(0)
+	}
+	return millis(seconds / float64(count))
+}
+
 func renderPlansSection(md *markdown.MarkDown, analysis PlanAnalysis) error {
-	sum := analysis.PassThrough + analysis.SimpleRouted + analysis.Complex + analysis.Unplannable
+	sum := analysis.PassThrough + analysis.SimpleRouted + analysis.Scatter + analysis.Complex + analysis.Unplannable
 	if sum == 0 {
 		return nil
 	}
 
 	md.PrintHeader("Query Planning Report", 2)
 
+	var executions int
+	var queryTime float64
+	for _, weight := range analysis.Weights {
+		executions += weight.Executions
+		queryTime += weight.QueryTime
+	}
+
+	// planalyze files without usage information can only be summarized by query structure
+	weighted := executions > 0
 	headers := []string{"Plan Complexity", "Count"}
-	rows := [][]string{
-		{planalyze.PassThrough.String(), strconv.Itoa(analysis.PassThrough)},
-		{planalyze.SimpleRouted.String(), strconv.Itoa(analysis.SimpleRouted)},
-		{planalyze.Complex.String(), strconv.Itoa(analysis.Complex)},
-		{planalyze.Unplannable.String(), strconv.Itoa(analysis.Unplannable)},
-		{"Total", strconv.Itoa(sum)},
+	if weighted {
+		headers = append(headers, "% of Queries", "Executions", "% of Executions", "% of Query Time")
+	}
+	var rows [][]string
+	addRow := func(name string, count int, weight PlanWeight) {
+		row := []string{name, strconv.Itoa(count)}
+		if weighted {
+			row = append(row,
+				percent(float64(count), float64(sum)),
+				humanize.Comma(int64(weight.Executions)),
+				percent(float64(weight.Executions), float64(executions)),
+				percent(weight.QueryTime, queryTime),
+			)
+		}
+		rows = append(rows, row)
 	}
+	addRow(planalyze.PassThrough.String(), analysis.PassThrough, analysis.Weights[planalyze.PassThrough])
+	addRow(planalyze.SimpleRouted.String(), analysis.SimpleRouted, analysis.Weights[planalyze.SimpleRouted])
+	addRow(planalyze.Scatter.String(), analysis.Scatter, analysis.Weights[planalyze.Scatter])
+	addRow(planalyze.Complex.String(), analysis.Complex, analysis.Weights[planalyze.Complex])
+	addRow(planalyze.Unplannable.String(), analysis.Unplannable, analysis.Weights[planalyze.Unplannable])
+	addRow("Total", sum, PlanWeight{Executions: executions, QueryTime: queryTime})
 	md.PrintTable(headers, rows)
 	md.NewLine()
 
+	renderUnplannableCauses(md, analysis.UnplannableCauses, weighted)
+	renderPlanReasons(md, analysis.Reasons, weighted, executions)
+	renderProblematicOperators(md, analysis.Operators, weighted, executions)
+	renderWriteAmplification(md, analysis.WriteAmplification, nil)
+
 	err := renderQueryPlans(md, analysis.simpleRouted, planalyze.SimpleRouted.String())
 	if err != nil {
 		return err
 	}
+	err = renderQueryPlans(md, analysis.scatter, planalyze.Scatter.String())
+	if err != nil {
+		return err
+	}
 	return renderQueryPlans(md, analysis.complex, planalyze.Complex.String())
 }
 
+func renderPlanReasons(md *markdown.MarkDown, reasons map[planalyze.ReasonKind]*PlanReasonCount, weighted bool, executions int) {
+	if len(reasons) == 0 {
+		return
+	}
+
+	md.PrintHeader("Plan Findings", 3)
+	headers := []string{"Finding", "Queries"}
+	if weighted {
+		headers = append(headers, "Executions", "% of Executions")
+	}
+	var rows [][]string
+	for _, kind := range planalyze.ReasonKinds {
+		count := reasons[kind]
+		if count == nil {
+			continue
+		}
+		row := []string{kind.String(), strconv.Itoa(count.Queries)}
+		if weighted {
+			row = append(row, humanize.Comma(int64(count.Executions)), percent(float64(count.Executions), float64(executions)))
+		}
+		rows = append(rows, row)
+	}
+	md.PrintTable(headers, rows)
+	md.NewLine()
+}
+
+// maxProblematicOperators is how many operators the problematic operators table shows
+const maxProblematicOperators = 10
+
+// renderProblematicOperators lists the operators behind the plan findings, most executed first,
+// so the exact variants of routes, joins and aggregations to work on stand out
+func renderProblematicOperators(md *markdown.MarkDown, operators map[string]*PlanReasonCount, weighted bool, executions int) {
+	if len(operators) == 0 {
+		return
+	}
+
+	names := slices.Collect(maps.Keys(operators))
+	sort.Slice(names, func(i, j int) bool {
+		a, b := operators[names[i]], operators[names[j]]
+		if a.Executions != b.Executions {
+			return a.Executions > b.Executions
+		}
+		if a.Queries != b.Queries {
+			return a.Queries > b.Queries
+		}
+		return names[i] < names[j]
+	})
+	if len(names) > maxProblematicOperators {
+		names = names[:maxProblematicOperators]
+	}
+
+	md.PrintHeader("Problematic Operators", 3)
+	headers := []string{"Operator", "Queries"}
+	if weighted {
+		headers = append(headers, "Executions", "% of Executions")
+	}
+	var rows [][]string
+	for _, name := range names {
+		count := operators[name]
+		row := []string{name, strconv.Itoa(count.Queries)}
+		if weighted {
+			row = append(row, humanize.Comma(int64(count.Executions)), percent(float64(count.Executions), float64(executions)))
+		}
+		rows = append(rows, row)
+	}
+	md.PrintTable(headers, rows)
+	md.NewLine()
+}
+
+// renderUnplannableCauses splits the unplannable queries into the ones Vitess does not support
+// and the ones that only need the columns of the tables, from a dbinfo file or a schema dump, to be planned
+func renderUnplannableCauses(md *markdown.MarkDown, causes map[planalyze.UnplannableCause]*PlanReasonCount, weighted bool) {
+	if len(causes) == 0 {
+		return
+	}
+
+	md.PrintHeader("Unplannable Queries", 3)
+	headers := []string{"Cause", "Queries"}
+	if weighted {
+		headers = append(headers, "Executions")
+	}
+	var rows [][]string
+	for _, cause := range []planalyze.UnplannableCause{planalyze.UnsupportedByVitess, planalyze.MissingSchemaInfo, planalyze.PlanningError} {
+		count := causes[cause]
+		if count == nil {
+			continue
+		}
+		row := []string{cause.String(), strconv.Itoa(count.Queries)}
+		if weighted {
+			row = append(row, humanize.Comma(int64(count.Executions)))
+		}
+		rows = append(rows, row)
+	}
+	md.PrintTable(headers, rows)
+	md.NewLine()
+	if causes[planalyze.MissingSchemaInfo] != nil {
+		md.Println("Queries missing schema info can usually be planned when `vt planalyze` is given the columns of the tables with `--dbinfo` or `--schema`.")
+		md.NewLine()
+	}
+}
+
+// renderWriteAmplification shows the statements vtgate runs to maintain vindexes and sequences.
+// When after is set, the two are shown side by side, as the cost before and after a vschema change.
+// A nil before means there is nothing to show.
+func renderWriteAmplification(md *markdown.MarkDown, before, after *planalyze.WriteAmplification) {
+	if before == nil {
+		return
+	}
+
+	md.PrintHeader("Write Amplification", 3)
+	headers := []string{"Operation", "Count"}
+	if after != nil {
+		headers = []string{"Operation", "Before", "After"}
+	}
+	var rows [][]string
+	addRow := func(name string, value func(wa *planalyze.WriteAmplification) string) {
+		row := []string{name, value(before)}
+		if after != nil {
+			row = append(row, value(after))
+		}
+		rows = append(rows, row)
+	}
+	count := func(f func(h planalyze.HiddenOperations) int) func(wa *planalyze.WriteAmplification) string {
+		return func(wa *planalyze.WriteAmplification) string {
+			return humanize.Comma(int64(f(wa.Hidden)))
+		}
+	}
+	addRow("Writes", func(wa *planalyze.WriteAmplification) string {
+		return humanize.Comma(int64(wa.Writes))
+	})
+	addRow("Lookup reads", count(func(h planalyze.HiddenOperations) int { return h.LookupReads }))
+	addRow("Lookup writes", count(func(h planalyze.HiddenOperations) int { return h.LookupWrites }))
+	addRow("Owned vindex reads", count(func(h planalyze.HiddenOperations) int { return h.OwnedVindexReads }))
+	addRow("Sequence fetches", count(func(h planalyze.HiddenOperations) int { return h.SequenceFetches }))
+	addRow("Statements per write", func(wa *planalyze.WriteAmplification) string {
+		if wa.Writes == 0 {
+			return "-"
+		}
+		return fmt.Sprintf("%.2f", wa.Factor)
+	})
+	md.PrintTable(headers, rows)
+	md.NewLine()
+}
+
+func percent(part, total float64) string {
+	if total == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%.1f%%", part*100/total)
+}
+
+func fanOutString(fanOut []planalyze.ShardFanOut) string {
+	buckets := make([]string, 0, len(fanOut))
+	for _, f := range fanOut {
+		unit := "shards"
+		if f.Shards == 1 {
+			unit = "shard"
+		}
+		buckets = append(buckets, fmt.Sprintf("%d %s in %d", f.Shards, unit, f.Samples))
+	}
+	return strings.Join(buckets, ", ")
+}
+
 func renderQueryPlans(md *markdown.MarkDown, queries []planalyze.AnalyzedQuery, title string) error {
 	for i, query := range queries {
 		if i == 0 {
 			md.Printf("# %s Queries\n\n", title)
 		}
 		md.Printf("## Query\n\n```sql\n%s\n```\n\n", query.QueryStructure)
+		if len(query.Reasons) > 0 {
+			md.Println("## Reasons")
+			md.NewLine()
+			for _, reason := range query.Reasons {
+				md.Printf("* %s\n", reason)
+			}
+			md.NewLine()
+		}
+		if h := query.Hidden; h != nil && h.Total() > 0 {
+			md.Printf("Hidden operations per execution: %d lookup reads, %d lookup writes, %d owned vindex reads, %d sequence fetches\n\n",
+				h.LookupReads, h.LookupWrites, h.OwnedVindexReads, h.SequenceFetches)
+		}
+		if len(query.FanOut) > 0 {
+			md.Printf("Shard fan-out of the sampled executions: %s\n\n", fanOutString(query.FanOut))
+		}
+		if query.Summary != nil {
+			renderPlanSummary(md, query.Summary)
+			continue
+		}
+
+		// planalyze files written before plans were summarized only have the plan itself
 		md.Println("## Plan\n\n```json")
 
 		// Indent the JSON output. If we don't do this, the json will be indented all wrong
@@ -279,7 +751,106 @@ func renderQueryPlans(md *markdown.MarkDown, queries []planalyze.AnalyzedQuery,
 	return nil
 }
 
+func renderPlanSummary(md *markdown.MarkDown, summary *planalyze.PlanSummary) {
+	md.Println("## Plan")
+	md.NewLine()
+
+	routes := fmt.Sprintf("%d routes", summary.Routes)
+	if summary.Routes == 1 {
+		routes = "1 route"
+	}
+	facts := []string{fmt.Sprintf("Depth %d", summary.Depth), fmt.Sprintf("%s (%d scatter)", routes, summary.ScatterRoutes)}
+	if len(summary.JoinTypes) > 0 {
+		facts = append(facts, "joins: "+strings.Join(summary.JoinTypes, ", "))
+	}
+	if summary.MemorySort {
+		facts = append(facts, "memory sort")
+	}
+	if summary.MemoryAggregation {
+		facts = append(facts, "memory aggregation")
+	}
+	md.Println(strings.Join(facts, ", "))
+	md.NewLine()
+
+	var rows [][]string
+	for _, op := range summary.Operators {
+		problematic := ""
+		if op.Problematic {
+			problematic = "yes"
+		}
+		rows = append(rows, []string{op.Operator, strconv.Itoa(op.Count), problematic})
+	}
+	md.PrintTable([]string{"Operator", "Count", "Problematic"}, rows)
+	md.NewLine()
+}
+
 func uniquefy(s []string) []string {
 	sort.Strings(s)
 	return slices.Compact(s)
 }
+
+func renderVSchemaComparison(md *markdown.MarkDown, c *planalyze.CompareOutput) {
+	if c == nil {
+		return
+	}
+
+	md.PrintHeader("VSchema Comparison", 2)
+	md.Printf("Plans with `%s` compared to plans with `%s`.\n\n", c.CompareVSchema, c.VSchema)
+
+	headers := []string{"Plan Complexity", "Queries Before", "Queries After", "Executions Before", "Executions After"}
+	var rows [][]string
+	for _, complexity := range planalyze.Complexities() {
+		before, after := c.Before.Get(complexity), c.After.Get(complexity)
+		rows = append(rows, []string{
+			complexity.String(),
+			strconv.Itoa(before.Queries),
+			strconv.Itoa(after.Queries),
+			humanize.Comma(int64(before.Executions)),
+			humanize.Comma(int64(after.Executions)),
+		})
+	}
+	md.PrintTable(headers, rows)
+	md.NewLine()
+
+	if c.BeforeWrites != nil || c.AfterWrites != nil {
+		before, after := c.BeforeWrites, c.AfterWrites
+		if before == nil {
+			before = &planalyze.WriteAmplification{}
+		}
+		if after == nil {
+			after = &planalyze.WriteAmplification{}
+		}
+		renderWriteAmplification(md, before, after)
+	}
+
+	if len(c.Transitions) == 0 {
+		md.Println("No query changed plan complexity.")
+		md.NewLine()
+		return
+	}
+
+	md.PrintHeader("Changed Plans", 3)
+	headers = []string{"From", "To", "Queries", "Executions", "Query Time (ms)"}
+	rows = nil
+	for _, t := range c.Transitions {
+		rows = append(rows, []string{
+			t.From.String(),
+			t.To.String(),
+			strconv.Itoa(t.Queries),
+			humanize.Comma(int64(t.Executions)),
+			millis(t.QueryTime),
+		})
+	}
+	md.PrintTable(headers, rows)
+	md.NewLine()
+	for _, q := range c.Changed {
+		md.PrintHeader(fmt.Sprintf("%s -> %s (%s executions)", q.Before, q.After, humanize.Comma(int64(q.UsageCount))), 4)
+		md.Println("```sql")
+		md.Println(q.QueryStructure)
+		md.Println("```")
+		if q.Error != "" {
+			md.Printf("Planning error: %s\n", q.Error)
+		}
+		md.NewLine()
+	}
+}
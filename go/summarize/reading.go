@@ -65,17 +65,21 @@ func readTracedFile(fileName string) (traceSummary, error) {
 
 type summarizer = func(s *Summary) error
 
+// txOutput is the output of `vt transactions`
+type txOutput struct {
+	FileType   string                         `json:"fileType"`
+	Signatures []transactions.Signature       `json:"signatures"`
+	Families   []transactions.Family          `json:"families"`
+	TableGraph []transactions.TableEdge       `json:"table_graph"`
+	Autocommit *transactions.AutocommitReport `json:"autocommit"`
+}
+
 func readTransactionFile(fileName string) (summarizer, error) {
 	c, err := os.ReadFile(fileName)
 	if err != nil {
 		return nil, fmt.Errorf("error opening file: %w", err)
 	}
 
-	type txOutput struct {
-		FileType   string                   `json:"fileType"`
-		Signatures []transactions.Signature `json:"signatures"`
-	}
-
 	var to txOutput
 	err = json.Unmarshal(c, &to)
 	if err != nil {
@@ -83,7 +87,7 @@ func readTransactionFile(fileName string) (summarizer, error) {
 	}
 	return func(s *Summary) error {
 		s.AnalyzedFiles = append(s.AnalyzedFiles, fileName)
-		return summarizeTransactions(s, to.Signatures)
+		return summarizeTransactions(s, to)
 	}, nil
 }
 
@@ -108,19 +112,65 @@ func readDBInfoFile(fileName string) (summarizer, error) {
 	return func(s *Summary) error {
 		s.AnalyzedFiles = append(s.AnalyzedFiles, fileName)
 		s.HasRowCount = true
+		schemas := tableSchemas(schemaInfo)
 		for _, ti := range schemaInfo.Tables {
-			table := s.GetTable(ti.Name)
-			if table == nil {
-				table = &TableSummary{Table: ti.Name}
-				s.AddTable(table)
-			}
+			table := s.dbInfoTable(ti, schemas)
+			table.Schema = ti.Schema
 			table.RowCount = ti.Rows
 			table.ReferencedTables = ti.ForeignKeys
+			table.Columns = ti.Columns
+			table.Size = ti.TableSize
 		}
+		s.Blockers = append(s.Blockers, compatibilityBlockers(schemaInfo)...)
+		s.VariableWarnings = append(s.VariableWarnings, globalVariableWarnings(schemaInfo.GlobalVariables)...)
 		return nil
 	}, nil
 }
 
+// dbInfoTable finds or adds the summary of a table of a dbinfo file, schemas is set when the file has several schemas
+func (s *Summary) dbInfoTable(ti *dbinfo.TableInfo, schemas map[string][]string) *TableSummary {
+	name := ti.Name
+	table := s.GetTable(name)
+	switch {
+	case schemas != nil:
+		// with several schemas every table is qualified, a table used without a schema is
+		// only attributed to it when no other schema has a table of that name
+		if s.schemasByTable == nil {
+			s.schemasByTable = map[string][]string{}
+		}
+		s.schemasByTable[ti.Name] = schemas[ti.Name]
+		name = ti.QualifiedName()
+		if qualified := s.GetTable(name); qualified != nil || len(schemas[ti.Name]) > 1 {
+			table = qualified
+		} else if table != nil {
+			table.Table = name
+		}
+	case table != nil && table.Schema != ti.Schema && table.Schema != "":
+		// a table with the same name in another schema is kept apart, under its qualified name
+		name = ti.QualifiedName()
+		table = s.GetTable(name)
+	}
+	if table == nil {
+		table = &TableSummary{Table: name}
+		s.AddTable(table)
+	}
+	return table
+}
+
+// tableSchemas lists the schemas that have a table of each name, or returns nil when the file has a single schema
+func tableSchemas(info *dbinfo.Info) map[string][]string {
+	schemas := map[string][]string{}
+	several := false
+	for _, ti := range info.Tables {
+		schemas[ti.Name] = append(schemas[ti.Name], ti.Schema)
+		several = several || ti.Schema != info.Tables[0].Schema
+	}
+	if !several {
+		return nil
+	}
+	return schemas
+}
+
 func readPlanalyzeFile(filename string) (summarizer, error) {
 	p, err := planalyze.ReadPlanalyzeFile(filename)
 	if err != nil {
@@ -132,3 +182,29 @@ func readPlanalyzeFile(filename string) (summarizer, error) {
 		return summarizePlanAnalyze(s, p)
 	}, nil
 }
+
+func readPlanalyzeCompareFile(filename string) (summarizer, error) {
+	c, err := planalyze.ReadCompareFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(s *Summary) error {
+		s.AnalyzedFiles = append(s.AnalyzedFiles, filename)
+		s.vschemaComparison = &c
+		return nil
+	}, nil
+}
+
+func readDBInfoDiffFile(filename string) (summarizer, error) {
+	d, err := dbinfo.ReadDiffFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(s *Summary) error {
+		s.AnalyzedFiles = append(s.AnalyzedFiles, filename)
+		s.schemaDiff = d
+		return nil
+	}, nil
+}
@@ -17,7 +17,10 @@ limitations under the License.
 package summarize
 
 import (
+	"os"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -28,3 +31,124 @@ func TestReadTransaction(t *testing.T) {
 	require.NoError(t, err)
 	assert.NotNil(t, file)
 }
+
+func TestSummarizeDBInfoSizes(t *testing.T) {
+	fn, err := readDBInfoFile("../testdata/dbInfo-output/customers-dbinfo.json")
+	require.NoError(t, err)
+
+	s, err := NewSummary("")
+	require.NoError(t, err)
+	s.TargetShardSize = 100_000_000
+
+	err = fn(s)
+	require.NoError(t, err)
+
+	sb := &strings.Builder{}
+	err = s.PrintMarkdown(sb, time.Date(2024, time.January, 1, 1, 2, 3, 0, time.UTC))
+	require.NoError(t, err)
+
+	expected, err := os.ReadFile("../testdata/summarize-output/customers-dbinfo.md")
+	require.NoError(t, err)
+	assert.Equal(t, string(expected), sb.String())
+	if t.Failed() {
+		_ = os.Mkdir("../testdata/expected", 0o755)
+		_ = os.WriteFile("../testdata/expected/customers-dbinfo.md", []byte(sb.String()), 0o644)
+	}
+}
+
+func TestSummarizeMultiSchemaDBInfo(t *testing.T) {
+	fn, err := readDBInfoFile("../testdata/dbInfo-output/multi-schema-dbinfo.json")
+	require.NoError(t, err)
+
+	s, err := NewSummary("")
+	require.NoError(t, err)
+	err = fn(s)
+	require.NoError(t, err)
+
+	var tables []string
+	for _, table := range s.Tables {
+		tables = append(tables, table.Schema+" "+table.Table)
+	}
+	assert.Equal(t, []string{"billing billing.customers", "billing billing.invoices", "shop shop.customers", "shop shop.orders"}, tables)
+	assert.Equal(t, 25000, s.GetTable("shop.customers").RowCount)
+
+	sb := &strings.Builder{}
+	err = s.PrintMarkdown(sb, time.Date(2024, time.January, 1, 1, 2, 3, 0, time.UTC))
+	require.NoError(t, err)
+	assert.Contains(t, sb.String(), "|shop.customers|InnoDB|25,000|")
+	assert.Contains(t, sb.String(), "|billing.customers|InnoDB|1,200|")
+}
+
+func TestSummarizeMultiSchemaDBInfoUsage(t *testing.T) {
+	fn, err := readDBInfoFile("../testdata/dbInfo-output/multi-schema-dbinfo.json")
+	require.NoError(t, err)
+
+	s, err := NewSummary("")
+	require.NoError(t, err)
+	// the queries do not qualify the tables, only shop has an orders table but both schemas have a customers table
+	s.AddTable(&TableSummary{Table: "orders", ReadQueryCount: 3})
+	s.AddTable(&TableSummary{Table: "customers", ReadQueryCount: 2})
+	err = fn(s)
+	require.NoError(t, err)
+
+	assert.Equal(t, 3, s.GetTable("shop.orders").ReadQueryCount)
+	assert.Nil(t, s.GetTable("orders"))
+	assert.Zero(t, s.GetTable("shop.customers").ReadQueryCount)
+	assert.Zero(t, s.GetTable("billing.customers").ReadQueryCount)
+	assert.Equal(t, map[string][]string{"customers": {"billing", "shop"}}, s.ambiguousTables())
+
+	// tables summarized after the dbinfo file are resolved the same way
+	assert.Equal(t, s.GetTable("billing.invoices"), s.findTable("invoices"))
+	assert.Equal(t, "customers", s.findTable("customers").Table)
+
+	sb := &strings.Builder{}
+	err = s.PrintMarkdown(sb, time.Date(2024, time.January, 1, 1, 2, 3, 0, time.UTC))
+	require.NoError(t, err)
+	assert.Contains(t, sb.String(), "## Ambiguous Tables")
+	assert.Contains(t, sb.String(), "|customers|billing, shop|")
+}
+
+func TestSummarizeSchemaDiff(t *testing.T) {
+	fn, err := readDBInfoDiffFile("../testdata/dbInfo-output/shop-dbinfo-diff.json")
+	require.NoError(t, err)
+
+	s, err := NewSummary("")
+	require.NoError(t, err)
+	err = fn(s)
+	require.NoError(t, err)
+
+	sb := &strings.Builder{}
+	err = s.PrintMarkdown(sb, time.Date(2024, time.January, 1, 1, 2, 3, 0, time.UTC))
+	require.NoError(t, err)
+
+	expected, err := os.ReadFile("../testdata/summarize-output/shop-dbinfo-diff.md")
+	require.NoError(t, err)
+	assert.Equal(t, string(expected), sb.String())
+	if t.Failed() {
+		_ = os.Mkdir("../testdata/expected", 0o755)
+		_ = os.WriteFile("../testdata/expected/shop-dbinfo-diff.md", []byte(sb.String()), 0o644)
+	}
+}
+
+func TestSummarizeCompatibilityBlockers(t *testing.T) {
+	fn, err := readDBInfoFile("../testdata/dbInfo-output/shop-objects-dbinfo.json")
+	require.NoError(t, err)
+
+	s, err := NewSummary("")
+	require.NoError(t, err)
+	err = fn(s)
+	require.NoError(t, err)
+	require.Len(t, s.Blockers, 6)
+
+	sb := &strings.Builder{}
+	err = s.PrintMarkdown(sb, time.Date(2024, time.January, 1, 1, 2, 3, 0, time.UTC))
+	require.NoError(t, err)
+
+	expected, err := os.ReadFile("../testdata/summarize-output/shop-objects-dbinfo.md")
+	require.NoError(t, err)
+	assert.Equal(t, string(expected), sb.String())
+	if t.Failed() {
+		_ = os.Mkdir("../testdata/expected", 0o755)
+		_ = os.WriteFile("../testdata/expected/shop-objects-dbinfo.md", []byte(sb.String()), 0o644)
+	}
+}
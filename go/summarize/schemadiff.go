@@ -0,0 +1,86 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package summarize
+
+import (
+	"fmt"
+
+	"github.com/dustin/go-humanize"
+
+	"github.com/vitessio/vt/go/dbinfo"
+	"github.com/vitessio/vt/go/markdown"
+)
+
+func renderSchemaDiff(md *markdown.MarkDown, d *dbinfo.Diff) {
+	if d == nil {
+		return
+	}
+
+	md.PrintHeader("Schema Changes", 2)
+	md.Printf("Schema changes from `%s` to `%s`.\n\n", d.Old, d.New)
+
+	var rows [][]string
+	for _, table := range d.AddedTables {
+		rows = append(rows, []string{table, "Table added", ""})
+	}
+	for _, table := range d.RemovedTables {
+		rows = append(rows, []string{table, "Table removed", ""})
+	}
+	for _, td := range d.ChangedTables {
+		rows = append(rows, tableDiffRows(td)...)
+	}
+
+	if len(rows) == 0 {
+		md.Println("No schema changes.")
+		md.NewLine()
+		return
+	}
+
+	md.PrintTable([]string{"Table", "Change", "Details"}, rows)
+}
+
+func tableDiffRows(td *dbinfo.TableDiff) [][]string {
+	var rows [][]string
+	names := func(change string, names []string) {
+		for _, name := range names {
+			rows = append(rows, []string{td.Table, change, "`" + name + "`"})
+		}
+	}
+	changes := func(change string, changes []*dbinfo.Change) {
+		for _, c := range changes {
+			rows = append(rows, []string{td.Table, change, fmt.Sprintf("`%s`: `%s` → `%s`", c.Name, c.Old, c.New)})
+		}
+	}
+
+	names("Column added", td.AddedColumns)
+	names("Column removed", td.RemovedColumns)
+	changes("Column changed", td.ChangedColumns)
+	names("Index added", td.AddedIndexes)
+	names("Index removed", td.RemovedIndexes)
+	changes("Index changed", td.ChangedIndexes)
+	names("Foreign key added", td.AddedForeignKeys)
+	names("Foreign key removed", td.RemovedForeignKeys)
+
+	if rg := td.RowGrowth; rg != nil {
+		details := fmt.Sprintf("%s → %s", humanize.Comma(int64(rg.Old)), humanize.Comma(int64(rg.New)))
+		if rg.Old > 0 {
+			details += fmt.Sprintf(" (%+.0f%%)", rg.Ratio()*100)
+		}
+		rows = append(rows, []string{td.Table, "Row count", details})
+	}
+	return rows
+}
@@ -265,7 +265,7 @@ func summarizeKeysQueries(summary *Summary, queries *keys.Output) error {
 
 	// Convert map to slice
 	for _, tblSummary := range tableSummaries {
-		table := summary.GetTable(tblSummary.Table)
+		table := summary.findTable(tblSummary.Table)
 		if table == nil {
 			summary.AddTable(tblSummary)
 			continue
@@ -25,6 +25,9 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"github.com/vitessio/vt/go/dbinfo"
+	"github.com/vitessio/vt/go/markdown"
 )
 
 func TestTableSummary(t *testing.T) {
@@ -131,3 +134,40 @@ func TestSummarizeKeysWithHotnessFile(t *testing.T) {
 		})
 	}
 }
+
+func TestColumnUsageShardingKeyWarnings(t *testing.T) {
+	summary := &TableSummary{
+		Table:          "orders",
+		ReadQueryCount: 4,
+		ColumnUses: map[string]ColumnUsage{
+			"id/JOIN":            {Percentage: 100, Count: 4},
+			"customer_id/JOIN":   {Percentage: 75, Count: 3},
+			"status/WHERE":       {Percentage: 50, Count: 2},
+			"created/WHERE":      {Percentage: 50, Count: 2},
+			"amount/WHERE RANGE": {Percentage: 25, Count: 1},
+		},
+		Columns: []*dbinfo.TableColumn{
+			{Name: "id", Type: "bigint", ColumnType: "bigint unsigned", KeyType: "pri"},
+			{Name: "customer_id", Type: "bigint", Sample: &dbinfo.ColumnSample{
+				Rows:      1000,
+				Distinct:  300,
+				TopValues: []dbinfo.ValueCount{{Value: "42", Count: 400}},
+			}},
+			{Name: "status", Type: "enum", ColumnType: "enum('new','paid')"},
+			{Name: "created", Type: "datetime", IsNullable: true},
+			{Name: "amount", Type: "decimal", ColumnType: "decimal(10,2)", IsNullable: true},
+		},
+	}
+
+	md := &markdown.MarkDown{}
+	renderColumnUsageTable(md, summary)
+	expected := `|Column|Position|Used %|Type|Sharding Key Warnings|
+|---|---|---|---|---|
+|id|JOIN|100%|bigint unsigned||
+|customer_id|JOIN|75%|bigint|hot shard: "42" is 40% of the sampled rows|
+|created|WHERE|50%|datetime|nullable, datetime values are mostly filtered by range, which scatters|
+|status|WHERE|50%|enum('new','paid')|enum has too few distinct values|
+|amount|WHERE RANGE|25%|decimal(10,2)||
+`
+	assert.Contains(t, md.String(), expected)
+}
@@ -24,11 +24,69 @@ func summarizePlanAnalyze(s *Summary, data planalyze.Output) (err error) {
 	s.planAnalysis = PlanAnalysis{
 		PassThrough:  len(data.PassThrough),
 		SimpleRouted: len(data.SimpleRouted),
+		Scatter:      len(data.Scatter),
 		Complex:      len(data.Complex),
 		Unplannable:  len(data.Unplannable),
+		Reasons:      map[planalyze.ReasonKind]*PlanReasonCount{},
+		Operators:    map[string]*PlanReasonCount{},
+
+		UnplannableCauses: map[planalyze.UnplannableCause]*PlanReasonCount{},
+
+		WriteAmplification: data.WriteAmplification,
+	}
+
+	// the buckets are in the order of the complexities, the Complexity of a query read from a file is not trusted
+	for complexity, queries := range [][]planalyze.AnalyzedQuery{data.PassThrough, data.SimpleRouted, data.Scatter, data.Complex, data.Unplannable} {
+		for _, query := range queries {
+			weight := &s.planAnalysis.Weights[complexity]
+			weight.Executions += query.UsageCount
+			weight.QueryTime += query.QueryTime
+			addReasons(s.planAnalysis.Reasons, query)
+			addOperators(s.planAnalysis.Operators, query)
+			if query.Cause != "" {
+				addCount(s.planAnalysis.UnplannableCauses, query.Cause, query)
+			}
+		}
 	}
 
 	s.planAnalysis.simpleRouted = append(s.planAnalysis.simpleRouted, data.SimpleRouted...)
+	s.planAnalysis.scatter = append(s.planAnalysis.scatter, data.Scatter...)
 	s.planAnalysis.complex = append(s.planAnalysis.complex, data.Complex...)
 	return nil
 }
+
+// addReasons counts a query once for each kind of reason found in its plan
+func addReasons(reasons map[planalyze.ReasonKind]*PlanReasonCount, query planalyze.AnalyzedQuery) {
+	seen := map[planalyze.ReasonKind]bool{}
+	for _, reason := range query.Reasons {
+		if seen[reason.Kind] {
+			continue
+		}
+		seen[reason.Kind] = true
+
+		addCount(reasons, reason.Kind, query)
+	}
+}
+
+// addOperators counts a query once for each problematic operator in its plan
+func addOperators(operators map[string]*PlanReasonCount, query planalyze.AnalyzedQuery) {
+	if query.Summary == nil {
+		return
+	}
+	for _, op := range query.Summary.Operators {
+		if op.Problematic {
+			addCount(operators, op.Operator, query)
+		}
+	}
+}
+
+func addCount[K comparable](counts map[K]*PlanReasonCount, key K, query planalyze.AnalyzedQuery) {
+	count := counts[key]
+	if count == nil {
+		count = &PlanReasonCount{}
+		counts[key] = count
+	}
+	count.Queries++
+	count.Executions += query.UsageCount
+	count.QueryTime += query.QueryTime
+}
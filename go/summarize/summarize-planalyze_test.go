@@ -24,6 +24,8 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"github.com/vitessio/vt/go/planalyze"
 )
 
 func TestSummarizePlans(t *testing.T) {
@@ -49,3 +51,108 @@ func TestSummarizePlans(t *testing.T) {
 		_ = os.WriteFile("../testdata/expected/bigger_slow_query_plan_report.md", []byte(sb.String()), 0o644)
 	}
 }
+
+func TestSummarizeVSchemaComparison(t *testing.T) {
+	fn, err := readPlanalyzeCompareFile("../testdata/planalyze-output/keys-log-vtgate-compare.json")
+	require.NoError(t, err)
+	sb := &strings.Builder{}
+	now := time.Date(2024, time.January, 1, 1, 2, 3, 0, time.UTC)
+
+	s, err := NewSummary("")
+	require.NoError(t, err)
+
+	err = fn(s)
+	require.NoError(t, err)
+
+	err = s.PrintMarkdown(sb, now)
+	require.NoError(t, err)
+
+	expected, err := os.ReadFile("../testdata/summarize-output/keys-log-vtgate-compare.md")
+	require.NoError(t, err)
+	assert.Equal(t, string(expected), sb.String())
+	if t.Failed() {
+		_ = os.Mkdir("../testdata/expected", 0o755)
+		_ = os.WriteFile("../testdata/expected/keys-log-vtgate-compare.md", []byte(sb.String()), 0o644)
+	}
+}
+
+func TestSummarizeWriteAmplification(t *testing.T) {
+	fn, err := readPlanalyzeFile("../testdata/planalyze-output/keys-writes-plan-report.json")
+	require.NoError(t, err)
+	sb := &strings.Builder{}
+	now := time.Date(2024, time.January, 1, 1, 2, 3, 0, time.UTC)
+
+	s, err := NewSummary("")
+	require.NoError(t, err)
+
+	err = fn(s)
+	require.NoError(t, err)
+
+	err = s.PrintMarkdown(sb, now)
+	require.NoError(t, err)
+
+	expected, err := os.ReadFile("../testdata/summarize-output/keys-writes-plan-report.md")
+	require.NoError(t, err)
+	assert.Equal(t, string(expected), sb.String())
+	if t.Failed() {
+		_ = os.Mkdir("../testdata/expected", 0o755)
+		_ = os.WriteFile("../testdata/expected/keys-writes-plan-report.md", []byte(sb.String()), 0o644)
+	}
+}
+
+func TestSummarizeFanOut(t *testing.T) {
+	fn, err := readPlanalyzeFile("../testdata/planalyze-output/customers-fanout-plan-report.json")
+	require.NoError(t, err)
+	sb := &strings.Builder{}
+	now := time.Date(2024, time.January, 1, 1, 2, 3, 0, time.UTC)
+
+	s, err := NewSummary("")
+	require.NoError(t, err)
+
+	err = fn(s)
+	require.NoError(t, err)
+
+	err = s.PrintMarkdown(sb, now)
+	require.NoError(t, err)
+
+	expected, err := os.ReadFile("../testdata/summarize-output/customers-fanout-plan-report.md")
+	require.NoError(t, err)
+	assert.Equal(t, string(expected), sb.String())
+	if t.Failed() {
+		_ = os.Mkdir("../testdata/expected", 0o755)
+		_ = os.WriteFile("../testdata/expected/customers-fanout-plan-report.md", []byte(sb.String()), 0o644)
+	}
+}
+
+func TestSummarizeUnplannableCauses(t *testing.T) {
+	s, err := NewSummary("")
+	require.NoError(t, err)
+
+	err = summarizePlanAnalyze(s, planalyze.Output{
+		Unplannable: []planalyze.AnalyzedQuery{
+			{QueryStructure: "q1", Complexity: planalyze.Unplannable, UsageCount: 3, Cause: planalyze.MissingSchemaInfo},
+			{QueryStructure: "q2", Complexity: planalyze.Unplannable, UsageCount: 2, Cause: planalyze.MissingSchemaInfo},
+			{QueryStructure: "q3", Complexity: planalyze.Unplannable, UsageCount: 1, Cause: planalyze.UnsupportedByVitess},
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, &PlanReasonCount{Queries: 2, PlanWeight: PlanWeight{Executions: 5}}, s.planAnalysis.UnplannableCauses[planalyze.MissingSchemaInfo])
+	assert.Equal(t, &PlanReasonCount{Queries: 1, PlanWeight: PlanWeight{Executions: 1}}, s.planAnalysis.UnplannableCauses[planalyze.UnsupportedByVitess])
+
+	sb := &strings.Builder{}
+	err = s.PrintMarkdown(sb, time.Date(2024, time.January, 1, 1, 2, 3, 0, time.UTC))
+	require.NoError(t, err)
+	assert.Contains(t, sb.String(), "|Unsupported by Vitess|1|1|\n|Missing schema info|2|5|\n")
+}
+
+func TestSummarizePlansOutOfRangeComplexity(t *testing.T) {
+	s, err := NewSummary("")
+	require.NoError(t, err)
+
+	// a hand edited file can have any complexity, the bucket the query is in decides
+	err = summarizePlanAnalyze(s, planalyze.Output{
+		Complex: []planalyze.AnalyzedQuery{{QueryStructure: "select 1", Complexity: 42, UsageCount: 3}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 3, s.planAnalysis.Weights[planalyze.Complex].Executions)
+}
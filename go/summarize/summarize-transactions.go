@@ -20,35 +20,131 @@ import (
 	"fmt"
 	"maps"
 	"slices"
+	"sort"
+	"strings"
 
 	"vitess.io/vitess/go/slice"
 
 	"github.com/vitessio/vt/go/transactions"
 )
 
-func summarizeTransactions(s *Summary, txs []transactions.Signature) error {
+// LongestTxCount is the number of transaction patterns shown in the longest transactions table
+const LongestTxCount = 10
+
+func summarizeTransactions(s *Summary, to txOutput) error {
+	txs, families, autocommit := to.Signatures, to.Families, to.Autocommit
+	if autocommit != nil {
+		if s.Autocommit == nil {
+			s.Autocommit = &transactions.AutocommitReport{Mode: autocommit.Mode, Default: autocommit.Default}
+		}
+		s.Autocommit.Merge(autocommit)
+	}
 	for _, tx := range txs {
 		patterns, joins := summarizeQueries(tx.Queries)
-		if len(joins) == 0 {
-			continue
+		if tx.Stats != nil {
+			s.LongestTxs = append(s.LongestTxs, TransactionSummary{
+				Count:   tx.Count,
+				Queries: patterns,
+				Joins:   joins,
+				Stats:   tx.Stats,
+			})
 		}
-
-		for _, p := range patterns {
-			table := s.GetTable(p.Table)
-			if table == nil {
-				s.AddTable(&TableSummary{Table: p.Table})
-			}
+		if len(families) > 0 || len(joins) == 0 {
+			// when the transactions were clustered, the families are shown instead of the signatures
+			continue
 		}
 
-		s.Transactions = append(s.Transactions, TransactionSummary{
+		s.addTransaction(TransactionSummary{
 			Count:   tx.Count,
 			Queries: patterns,
 			Joins:   joins,
+			Stats:   tx.Stats,
+		})
+	}
+
+	for _, family := range families {
+		patterns, joins := summarizeQueries(family.Representative.Queries)
+		if len(joins) == 0 && len(family.Variants) == 0 {
+			continue
+		}
+		s.addTransaction(TransactionSummary{
+			Count:    family.Count,
+			Queries:  patterns,
+			Joins:    joins,
+			Stats:    family.Stats,
+			Variants: slice.Map(family.Variants, describeVariant),
 		})
 	}
+
+	s.addTableGraph(to.TableGraph)
+
+	sort.SliceStable(s.LongestTxs, func(i, j int) bool {
+		return s.LongestTxs[i].Stats.MaxDuration > s.LongestTxs[j].Stats.MaxDuration
+	})
+	if len(s.LongestTxs) > LongestTxCount {
+		s.LongestTxs = s.LongestTxs[:LongestTxCount]
+	}
 	return nil
 }
 
+func (s *Summary) addTransaction(tx TransactionSummary) {
+	for _, p := range tx.Queries {
+		table := s.findTable(p.Table)
+		if table == nil {
+			s.AddTable(&TableSummary{Table: p.Table})
+		}
+	}
+	s.Transactions = append(s.Transactions, tx)
+}
+
+// addTableGraph merges the co-modification graph of a transactions file into the summary
+func (s *Summary) addTableGraph(edges []transactions.TableEdge) {
+	for _, edge := range edges {
+		for _, table := range []string{edge.Table1, edge.Table2} {
+			if s.findTable(table) == nil {
+				s.AddTable(&TableSummary{Table: table})
+			}
+		}
+		idx := slices.IndexFunc(s.TxGraph, func(e transactions.TableEdge) bool {
+			return e.Table1 == edge.Table1 && e.Table2 == edge.Table2
+		})
+		if idx == -1 {
+			s.TxGraph = append(s.TxGraph, edge)
+			continue
+		}
+		merged := &s.TxGraph[idx]
+		merged.Count += edge.Count
+		for _, col := range edge.Columns {
+			colIdx := slices.IndexFunc(merged.Columns, func(c transactions.ColumnLink) bool {
+				return c.Col1 == col.Col1 && c.Col2 == col.Col2
+			})
+			if colIdx == -1 {
+				merged.Columns = append(merged.Columns, col)
+			} else {
+				merged.Columns[colIdx].Count += col.Count
+			}
+		}
+	}
+	sort.SliceStable(s.TxGraph, func(i, j int) bool {
+		return s.TxGraph[i].Count > s.TxGraph[j].Count
+	})
+}
+
+// describeVariant explains how a signature in a family differs from the family's representative
+func describeVariant(v transactions.Variant) string {
+	var diffs []string
+	for _, q := range v.Missing {
+		diffs = append(diffs, fmt.Sprintf("without %s on `%s`", strings.ToUpper(q.Op), q.AffectedTable))
+	}
+	for _, q := range v.Extra {
+		diffs = append(diffs, fmt.Sprintf("with extra %s on `%s`", strings.ToUpper(q.Op), q.AffectedTable))
+	}
+	if len(diffs) == 0 {
+		diffs = append(diffs, "same statements, repeated a different number of times or with other shared values")
+	}
+	return fmt.Sprintf("%d times (similarity %.2f): %s", v.Count, v.Similarity, strings.Join(diffs, ", "))
+}
+
 func summarizeQueries(queries []transactions.Query) (patterns []QueryPattern, joins [][]string) {
 	columnJoins := map[int][]string{}
 	for _, q := range queries {
@@ -57,11 +153,19 @@ func summarizeQueries(queries []transactions.Query) (patterns []QueryPattern, jo
 				columnJoins[predicate.Val] = append(columnJoins[predicate.Val], fmt.Sprintf("%s.%s", q.AffectedTable, predicate.Col))
 			}
 		}
+		for _, assignment := range q.Assignments {
+			if assignment.Val >= 0 {
+				columnJoins[assignment.Val] = append(columnJoins[assignment.Val], fmt.Sprintf("%s.%s", q.AffectedTable, assignment.Col))
+			}
+		}
 		patterns = append(patterns, QueryPattern{
 			Type:           q.Op,
 			Table:          q.AffectedTable,
 			Predicates:     slice.Map(q.Predicates, func(p transactions.PredicateInfo) string { return p.String() }),
 			UpdatedColumns: q.UpdatedColumns,
+			Assignments:    slice.Map(q.Assignments, func(a transactions.Assignment) string { return a.String() }),
+			Repeat:         q.Repeat,
+			MaxRepeat:      q.MaxRepeat,
 		})
 	}
 	joinKeys := slices.Collect(maps.Keys(columnJoins))
@@ -24,6 +24,8 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"github.com/vitessio/vt/go/transactions"
 )
 
 func TestSummarizeTransactionsFile(t *testing.T) {
@@ -49,3 +51,37 @@ func TestSummarizeTransactionsFile(t *testing.T) {
 		_ = os.WriteFile("../testdata/expected/transactions-summary.md", []byte(sb.String()), 0o644)
 	}
 }
+
+func TestSummarizeTransactionFamilies(t *testing.T) {
+	orders := transactions.Query{
+		Op:            "insert",
+		AffectedTable: "orders",
+		Assignments:   []transactions.Assignment{{Col: "id", Op: transactions.AssignOp, Val: 0}},
+	}
+	lines := transactions.Query{
+		Op:            "insert",
+		AffectedTable: "order_line",
+		Assignments:   []transactions.Assignment{{Col: "order_id", Op: transactions.AssignOp, Val: 0}},
+		Repeat:        3,
+		MaxRepeat:     5,
+	}
+	customer := transactions.Query{Op: "update", AffectedTable: "customer"}
+
+	s, err := NewSummary("")
+	require.NoError(t, err)
+	err = summarizeTransactions(s, txOutput{Families: []transactions.Family{{
+		Count:          7,
+		Representative: &transactions.Signature{Count: 5, Queries: []transactions.Query{orders, lines, customer}},
+		Variants: []transactions.Variant{
+			{Count: 2, Similarity: 0.8, Missing: []transactions.Query{customer}},
+		},
+	}}})
+	require.NoError(t, err)
+
+	require.Len(t, s.Transactions, 1)
+	tx := s.Transactions[0]
+	assert.Equal(t, 7, tx.Count)
+	assert.Equal(t, [][]string{{"orders.id", "order_line.order_id"}}, tx.Joins)
+	assert.Equal(t, []string{"2 times (similarity 0.80): without UPDATE on `customer`"}, tx.Variants)
+	assert.Equal(t, " (x3-5)", repeatSuffix(tx.Queries[1]))
+}
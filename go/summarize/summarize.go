@@ -47,7 +47,10 @@ type SummaryOutput struct {
 
 type summaryWorker = func(s *Summary) error
 
-func Run(files []string, hotMetric string, showGraph bool, outputFormat string, launchWebServer bool) {
+// DefaultTargetShardSize is the size that shards are usually kept under, 250GB
+const DefaultTargetShardSize = 250_000_000_000
+
+func Run(files []string, hotMetric string, showGraph bool, outputFormat string, launchWebServer bool, targetShardSize uint64) {
 	var traces []traceSummary
 	var workers []summaryWorker
 
@@ -72,6 +75,10 @@ func Run(files []string, hotMetric string, showGraph bool, outputFormat string,
 			w, err = readKeysFile(file)
 		case data.PlanalyzeFile:
 			w, err = readPlanalyzeFile(file)
+		case data.PlanalyzeCompareFile:
+			w, err = readPlanalyzeCompareFile(file)
+		case data.DBInfoDiffFile:
+			w, err = readDBInfoDiffFile(file)
 		default:
 			err = errors.New("unknown file type")
 		}
@@ -87,7 +94,7 @@ func Run(files []string, hotMetric string, showGraph bool, outputFormat string,
 
 	traceCount := len(traces)
 	if traceCount <= 0 {
-		s, err := printSummary(hotMetric, workers, outputFormat, launchWebServer)
+		s, err := printSummary(hotMetric, workers, outputFormat, launchWebServer, targetShardSize)
 		exitIfError(err)
 		if showGraph {
 			err := renderQueryGraph(s)
@@ -116,11 +123,12 @@ func exitIfError(err error) {
 	os.Exit(1)
 }
 
-func printSummary(hotMetric string, workers []summaryWorker, outputFormat string, launchWebServer bool) (*Summary, error) {
+func printSummary(hotMetric string, workers []summaryWorker, outputFormat string, launchWebServer bool, targetShardSize uint64) (*Summary, error) {
 	s, err := NewSummary(hotMetric)
 	if err != nil {
 		return nil, err
 	}
+	s.TargetShardSize = targetShardSize
 	for _, worker := range workers {
 		err := worker(s)
 		if err != nil {
@@ -28,24 +28,43 @@ import (
 	"github.com/vitessio/vt/go/keys"
 	"github.com/vitessio/vt/go/markdown"
 	"github.com/vitessio/vt/go/planalyze"
+	"github.com/vitessio/vt/go/transactions"
 )
 
 type (
 	Summary struct {
-		Tables        []*TableSummary
-		Failures      []FailuresSummary
-		Transactions  []TransactionSummary
-		HotQueries    []keys.QueryAnalysisResult
-		planAnalysis  PlanAnalysis
+		Tables       []*TableSummary
+		Failures     []FailuresSummary
+		Transactions []TransactionSummary
+		LongestTxs   []TransactionSummary
+		Autocommit   *transactions.AutocommitReport
+		TxGraph      []transactions.TableEdge
+		HotQueries   []keys.QueryAnalysisResult
+		planAnalysis PlanAnalysis
+		// vschemaComparison is set when a planalyze comparison between two vschemas is summarized
+		vschemaComparison *planalyze.CompareOutput
+		// schemaDiff is set when a diff between two dbinfo files is summarized
+		schemaDiff    *dbinfo.Diff
 		hotQueryFn    getMetric
 		AnalyzedFiles []string
 		queryGraph    queryGraph
 		Joins         []joinDetails
 		HasRowCount   bool
+		// Blockers are the routines, triggers, events and views of a dbinfo file
+		Blockers []CompatibilityBlocker
+		// VariableWarnings are the global variables of a dbinfo file with values that do not suit Vitess
+		VariableWarnings []VariableWarning
+		// schemasByTable lists the schemas of a dbinfo file with several schemas that have a table of that name
+		schemasByTable map[string][]string
+
+		// TargetShardSize is the size in bytes a shard should not grow beyond, used to estimate how many shards tables need
+		TargetShardSize uint64
 	}
 
 	TableSummary struct {
-		Table            string
+		Table string
+		// Schema is set when the table comes from a dbinfo file with several schemas
+		Schema           string
 		ReadQueryCount   int
 		WriteQueryCount  int
 		ColumnUses       map[string]ColumnUsage
@@ -53,6 +72,12 @@ type (
 		Failed           bool
 		RowCount         int
 		ReferencedTables []*dbinfo.ForeignKey
+
+		// Columns are the column definitions from a dbinfo file, when it has them
+		Columns []*dbinfo.TableColumn
+
+		// Size is the storage of the table from a dbinfo file
+		Size dbinfo.TableSize
 	}
 
 	TransactionSummary struct {
@@ -62,6 +87,12 @@ type (
 		// Joins contain a list of columns that are joined together.
 		// Each outer slice is one set of columns that are joined together.
 		Joins [][]string
+
+		// Stats is nil if the transactions file was produced without timing information
+		Stats *transactions.TxStats
+
+		// Variants describe the other signatures of a family, when the transactions were clustered
+		Variants []string
 	}
 
 	QueryPattern struct {
@@ -69,15 +100,45 @@ type (
 		Table          string
 		Predicates     []string
 		UpdatedColumns []string
+		Assignments    []string
+		Repeat         int
+		MaxRepeat      int
+	}
+
+	PlanWeight struct {
+		Executions int
+		QueryTime  float64
+	}
+
+	// PlanReasonCount is the number of queries with a reason in their plan, and the workload they represent
+	PlanReasonCount struct {
+		Queries int
+		PlanWeight
 	}
 
 	PlanAnalysis struct {
 		PassThrough  int
 		SimpleRouted int
+		Scatter      int
 		Complex      int
 		Unplannable  int
 
+		// Weights holds the workload represented by the queries of each complexity, indexed by planalyze.PlanComplexity
+		Weights [planalyze.Unplannable + 1]PlanWeight
+
+		// Reasons counts the queries by the operations that make their plans expensive
+		Reasons map[planalyze.ReasonKind]*PlanReasonCount
+
+		// Operators counts the queries by the problematic operators in their plans, like scatter routes or joins
+		Operators map[string]*PlanReasonCount
+
+		// UnplannableCauses counts the unplannable queries by why they could not be planned
+		UnplannableCauses map[planalyze.UnplannableCause]*PlanReasonCount
+
+		WriteAmplification *planalyze.WriteAmplification
+
 		simpleRouted []planalyze.AnalyzedQuery
+		scatter      []planalyze.AnalyzedQuery
 		complex      []planalyze.AnalyzedQuery
 	}
 )
@@ -114,10 +175,19 @@ func (s *Summary) PrintMarkdown(out io.Writer, now time.Time) error {
 	if err != nil {
 		return err
 	}
+	renderVSchemaComparison(md, s.vschemaComparison)
 	renderHotQueries(md, s.HotQueries, s.hotQueryFn)
 	renderTableUsage(md, s.Tables, s.HasRowCount)
+	renderTableSizes(md, s.Tables, s.TargetShardSize)
+	renderCompatibilityBlockers(md, s.Blockers)
+	renderGlobalVariableWarnings(md, s.VariableWarnings)
+	renderAmbiguousTables(md, s.ambiguousTables())
+	renderSchemaDiff(md, s.schemaDiff)
 	renderTablesJoined(md, s)
+	renderAutocommit(md, s.Autocommit)
+	renderLongestTransactions(md, s.LongestTxs)
 	renderTransactions(md, s.Transactions)
+	renderTablesModifiedTogether(md, s.TxGraph)
 	renderFailures(md, s.Failures)
 
 	_, err = md.WriteTo(out)
@@ -136,6 +206,30 @@ func (s *Summary) GetTable(name string) *TableSummary {
 	return nil
 }
 
+// findTable finds the table a query uses. When a dbinfo file has several schemas, its tables are qualified,
+// and an unqualified name is the table of the only schema that has it.
+func (s *Summary) findTable(name string) *TableSummary {
+	if table := s.GetTable(name); table != nil {
+		return table
+	}
+	if schemas := s.schemasByTable[name]; len(schemas) == 1 {
+		return s.GetTable(schemas[0] + "." + name)
+	}
+	return nil
+}
+
+// ambiguousTables lists the tables used without a schema that several schemas of a dbinfo file have,
+// their usage cannot be attributed to one of them
+func (s *Summary) ambiguousTables() map[string][]string {
+	ambiguous := map[string][]string{}
+	for _, table := range s.Tables {
+		if schemas := s.schemasByTable[table.Table]; len(schemas) > 1 {
+			ambiguous[table.Table] = schemas
+		}
+	}
+	return ambiguous
+}
+
 func (s *Summary) AddTable(table *TableSummary) {
 	s.Tables = append(s.Tables, table)
 }
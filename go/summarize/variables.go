@@ -0,0 +1,128 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package summarize
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/vitessio/vt/go/markdown"
+)
+
+// VariableWarning is a global variable from a dbinfo file set to a value that does not work well with Vitess
+type VariableWarning struct {
+	Variable string
+	Value    string
+	Warning  string
+}
+
+type variableCheck func(value string) string
+
+func expectValue(expected, warning string) variableCheck {
+	return func(value string) string {
+		if strings.EqualFold(value, expected) {
+			return ""
+		}
+		return warning
+	}
+}
+
+func checkSQLMode(value string) string {
+	var issues []string
+	for _, mode := range strings.Split(strings.ToUpper(value), ",") {
+		switch mode {
+		case "ANSI_QUOTES", "ANSI":
+			issues = append(issues, "Vitess parses double quoted strings as string literals, not identifiers")
+		case "PIPES_AS_CONCAT":
+			issues = append(issues, "Vitess parses || as OR, not as concatenation")
+		case "NO_BACKSLASH_ESCAPES":
+			issues = append(issues, "Vitess treats backslashes in strings as escape characters")
+		}
+	}
+	return strings.Join(issues, ", ")
+}
+
+func checkVersion(value string) string {
+	major, _, _ := strings.Cut(value, ".")
+	if v, err := strconv.Atoi(major); err == nil && v < 8 {
+		return "Vitess supports MySQL 8.0 and later"
+	}
+	return ""
+}
+
+func checkCharset(value string) string {
+	if strings.HasPrefix(strings.ToLower(value), "utf8mb4") {
+		return ""
+	}
+	return "vtgate uses utf8mb4 unless its collation is configured to match the server"
+}
+
+// variableChecks are the checks run on the global variables of a dbinfo file, by variable name
+func variableChecks() map[string]variableCheck {
+	return map[string]variableCheck{
+		"version":                  checkVersion,
+		"binlog_format":            expectValue("ROW", "VReplication needs row based binary logs"),
+		"binlog_row_image":         expectValue("FULL", "VReplication needs full row images"),
+		"log_bin":                  expectValue("ON", "VReplication and backups need binary logging enabled"),
+		"gtid_mode":                expectValue("ON", "Vitess needs GTIDs for replication and VReplication"),
+		"enforce_gtid_consistency": expectValue("ON", "Vitess needs GTIDs for replication and VReplication"),
+		"sql_mode":                 checkSQLMode,
+		"character_set_server":     checkCharset,
+		"collation_server":         checkCharset,
+		"lower_case_table_names": expectValue("0",
+			"VSchema table names are case sensitive, queries must use the names as they are stored"),
+		"transaction_isolation": func(value string) string {
+			if strings.EqualFold(value, "SERIALIZABLE") {
+				return "Isolation only holds within a shard, transactions across shards are not serializable"
+			}
+			return ""
+		},
+	}
+}
+
+// globalVariableWarnings checks the global variables of a dbinfo file, variables that were not collected are not checked
+func globalVariableWarnings(variables map[string]string) []VariableWarning {
+	var warnings []VariableWarning
+	for name, check := range variableChecks() {
+		value, ok := variables[name]
+		if !ok {
+			continue
+		}
+		if warning := check(value); warning != "" {
+			warnings = append(warnings, VariableWarning{Variable: name, Value: value, Warning: warning})
+		}
+	}
+	sort.Slice(warnings, func(i, j int) bool {
+		return warnings[i].Variable < warnings[j].Variable
+	})
+	return warnings
+}
+
+func renderGlobalVariableWarnings(md *markdown.MarkDown, warnings []VariableWarning) {
+	if len(warnings) == 0 {
+		return
+	}
+
+	md.PrintHeader("Global Variable Warnings", 2)
+	headers := []string{"Variable", "Value", "Warning"}
+	rows := make([][]string, 0, len(warnings))
+	for _, w := range warnings {
+		rows = append(rows, []string{w.Variable, w.Value, w.Warning})
+	}
+	md.PrintTable(headers, rows)
+}
@@ -0,0 +1,41 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package summarize
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGlobalVariableWarnings(t *testing.T) {
+	warnings := globalVariableWarnings(map[string]string{
+		"binlog_format":          "MIXED",
+		"binlog_row_image":       "FULL",
+		"sql_mode":               "PIPES_AS_CONCAT,STRICT_TRANS_TABLES",
+		"character_set_server":   "latin1",
+		"lower_case_table_names": "0",
+		"version":                "8.0.36",
+	})
+	assert.Equal(t, []VariableWarning{
+		{Variable: "binlog_format", Value: "MIXED", Warning: "VReplication needs row based binary logs"},
+		{Variable: "character_set_server", Value: "latin1", Warning: "vtgate uses utf8mb4 unless its collation is configured to match the server"},
+		{Variable: "sql_mode", Value: "PIPES_AS_CONCAT,STRICT_TRANS_TABLES", Warning: "Vitess parses || as OR, not as concatenation"},
+	}, warnings)
+
+	assert.Empty(t, globalVariableWarnings(nil))
+}
@@ -0,0 +1,177 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package transactions
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"vitess.io/vitess/go/vt/sqlparser"
+
+	"github.com/vitessio/vt/go/data"
+)
+
+// The values accepted for the autocommit setting
+const (
+	AutocommitAuto = "auto"
+	AutocommitOn   = "on"
+	AutocommitOff  = "off"
+)
+
+// AutocommitReport describes which autocommit setting the analysis assumed,
+// and how many connections ran statements in each mode
+type AutocommitReport struct {
+	// Mode is the configured autocommit setting: on, off or auto
+	Mode string `json:"mode"`
+	// Default is the autocommit setting connections start with
+	Default bool `json:"default"`
+
+	// On and Off count the connections that ran all their statements with autocommit enabled or disabled.
+	// Mixed counts the connections that changed autocommit between statements.
+	On    int `json:"on"`
+	Off   int `json:"off"`
+	Mixed int `json:"mixed"`
+}
+
+// ValidateAutocommitMode returns an error if mode is not one of on, off or auto
+func ValidateAutocommitMode(mode string) error {
+	switch mode {
+	case AutocommitAuto, AutocommitOn, AutocommitOff:
+		return nil
+	default:
+		return fmt.Errorf("invalid autocommit setting %q, expected one of on, off or auto", mode)
+	}
+}
+
+// Merge adds the connection counts of other to the receiver
+func (r *AutocommitReport) Merge(other *AutocommitReport) {
+	if other == nil {
+		return
+	}
+	r.On += other.On
+	r.Off += other.Off
+	r.Mixed += other.Mixed
+}
+
+// addConnection counts a connection, based on the autocommit modes its statements ran with
+func (r *AutocommitReport) addConnection(c *Connection) {
+	switch {
+	case c.ranWithAutocommit && c.ranWithoutAutocommit:
+		r.Mixed++
+	case c.ranWithAutocommit:
+		r.On++
+	case c.ranWithoutAutocommit:
+		r.Off++
+	}
+}
+
+// getAutocommitStatus returns the autocommit setting of the session after the SET statement.
+// Settings with global scope or user variables don't change the session, so they are ignored.
+func getAutocommitStatus(set *sqlparser.Set, oldState bool) bool {
+	for _, expr := range set.Exprs {
+		if expr.Var.Name.Lowered() != "autocommit" {
+			continue
+		}
+		if expr.Var.Scope != sqlparser.NoScope && expr.Var.Scope != sqlparser.SessionScope {
+			continue
+		}
+		switch val := expr.Expr.(type) {
+		case sqlparser.BoolVal:
+			return bool(val)
+		case *sqlparser.Literal:
+			str := strings.ToLower(val.Val)
+			return str == "1" || str == "on" || str == "true"
+		}
+	}
+	return oldState
+}
+
+// getDefaultAutocommit returns the autocommit setting connections start with
+func (s *state) getDefaultAutocommit(cfg Config) bool {
+	switch cfg.Autocommit {
+	case AutocommitOn:
+		return true
+	case AutocommitOff:
+		return false
+	default:
+		return s.getAutocommitGuess(cfg)
+	}
+}
+
+func (s *state) getAutocommitGuess(cfg Config) bool {
+	// Figure out if autocommit is enabled by looking at the start of the log.
+	// If we see:
+	// 1. SET autocommit = 1/0, the application configures it explicitly, so we use that value
+	// 2. COMMIT or ROLLBACK on a connection without a preceding BEGIN, the transaction was
+	//    started implicitly, so autocommit is disabled
+	// 3. Only BEGIN ... COMMIT blocks, or no transactions at all, we assume autocommit is
+	//    enabled because that is the MySQL default
+	count := 1000
+	defaultAutocommit := true
+	explicitTx := map[int]bool{}
+	loader := cfg.Loader.Load(cfg.FileName)
+	defer func() {
+		err := loader.Close()
+		if err != nil {
+			panic(err.Error())
+		}
+	}()
+	_ = data.ForeachSQLQueryWithEvents(loader, func(query data.Query) error {
+		count--
+		if count == 0 {
+			// enough already. we'll assume autocommit is enabled because that is the default
+			return io.EOF
+		}
+
+		if query.Type == data.ConnectionEvent {
+			delete(explicitTx, query.ConnectionID)
+			return nil
+		}
+
+		stmt := s.parse(query.Query)
+		if stmt == nil {
+			return nil
+		}
+
+		switch stmt := stmt.(type) {
+		case *sqlparser.Set:
+			if isAutocommitSet(stmt) {
+				defaultAutocommit = getAutocommitStatus(stmt, defaultAutocommit)
+				return io.EOF
+			}
+		case *sqlparser.Begin:
+			explicitTx[query.ConnectionID] = true
+		case *sqlparser.Commit, *sqlparser.Rollback:
+			if !explicitTx[query.ConnectionID] {
+				// no BEGIN seen, so autocommit is disabled
+				defaultAutocommit = false
+				return io.EOF
+			}
+			delete(explicitTx, query.ConnectionID)
+		}
+
+		return nil
+	})
+	return defaultAutocommit
+}
+
+// isAutocommitSet returns true if the SET statement changes autocommit for the session
+func isAutocommitSet(set *sqlparser.Set) bool {
+	// when the statement doesn't set autocommit, the result is the old state
+	return getAutocommitStatus(set, true) == getAutocommitStatus(set, false)
+}
@@ -0,0 +1,264 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package transactions
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// DefaultClusterSimilarity is the similarity a signature needs with a family's representative to join the family
+const DefaultClusterSimilarity = 0.7
+
+// ValidateClusterSimilarity returns an error if similarity is not above 0 and at most 1.
+// Above 1 no signature would join a family, and at 0 or below all of them would.
+func ValidateClusterSimilarity(similarity float64) error {
+	if similarity <= 0 || similarity > 1 {
+		return fmt.Errorf("invalid cluster similarity %v, expected a value above 0 and at most 1", similarity)
+	}
+	return nil
+}
+
+type (
+	// Family is a group of similar transaction signatures.
+	// The representative is the most common signature of the family.
+	Family struct {
+		// Count is the number of transactions in all the signatures of the family
+		Count          int        `json:"count"`
+		Representative *Signature `json:"representative"`
+		Variants       []Variant  `json:"variants,omitempty"`
+		Stats          *TxStats   `json:"stats,omitempty"`
+	}
+
+	// Variant is a signature in a family, described by how it differs from the representative
+	Variant struct {
+		Count      int     `json:"count"`
+		Similarity float64 `json:"similarity"`
+		// Missing are the queries of the representative that this variant does not run
+		Missing []Query `json:"missing,omitempty"`
+		// Extra are the queries this variant runs that the representative does not
+		Extra []Query `json:"extra,omitempty"`
+	}
+)
+
+// collapseRepeats merges consecutive identical queries into a single query with a repeat count.
+// Values only used once are ignored when comparing the queries, so a statement run in a loop
+// with a new value each time is still collapsed. Clean up the signature afterwards.
+func (tx *Signature) collapseRepeats() *Signature {
+	uses := tx.valueUses()
+	var queries []Query
+	for _, query := range tx.Queries {
+		last := len(queries) - 1
+		if last >= 0 && queries[last].withoutSingleValues(uses).equalsIgnoringRepeat(query.withoutSingleValues(uses)) {
+			queries[last].Repeat = queries[last].times() + query.times()
+			continue
+		}
+		queries = append(queries, query)
+	}
+	tx.Queries = queries
+	return tx
+}
+
+// withoutSingleValues returns a copy of the query where the values used only once are -1, like CleanUp numbers them
+func (tx Query) withoutSingleValues(uses map[int]int) Query {
+	tx.Predicates = slices.Clone(tx.Predicates)
+	for i := range tx.Predicates {
+		if uses[tx.Predicates[i].Val] == 1 {
+			tx.Predicates[i].Val = -1
+		}
+	}
+	tx.Assignments = slices.Clone(tx.Assignments)
+	for i := range tx.Assignments {
+		if uses[tx.Assignments[i].Val] == 1 {
+			tx.Assignments[i].Val = -1
+		}
+	}
+	return tx
+}
+
+// times returns how many times the query was run in a row
+func (tx Query) times() int {
+	return max(tx.Repeat, 1)
+}
+
+func (tx Query) equalsIgnoringRepeat(other Query) bool {
+	other.Repeat = tx.Repeat
+	other.MaxRepeat = tx.MaxRepeat
+	return tx.Equals(other)
+}
+
+// shape describes a query without its values, so queries can be compared across signatures
+// where the same value got a different number
+func (tx Query) shape() string {
+	var sb strings.Builder
+	sb.WriteString(tx.Op)
+	sb.WriteString(" ")
+	sb.WriteString(tx.AffectedTable)
+	for _, col := range tx.UpdatedColumns {
+		sb.WriteString(" " + col)
+	}
+	for _, pred := range tx.Predicates {
+		sb.WriteString(" " + pred.Table + "." + pred.Col + " " + pred.Op.ToString())
+	}
+	for _, assignment := range tx.Assignments {
+		sb.WriteString(" " + assignment.Col + " " + assignment.Op)
+	}
+	return sb.String()
+}
+
+// clusterSignatures groups similar signatures into families.
+// Signatures are handled from the most to the least common, and each one joins the family
+// whose representative it is most similar to, if the similarity is at least minSimilarity.
+func clusterSignatures(signatures []*Signature, minSimilarity float64) []*Family {
+	sorted := make([]*Signature, len(signatures))
+	copy(sorted, signatures)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Count > sorted[j].Count
+	})
+
+	var families []*Family
+	for _, sig := range sorted {
+		var best *Family
+		var bestAlignment alignment
+		for _, family := range families {
+			a := align(family.Representative.Queries, sig.Queries)
+			if a.similarity >= minSimilarity && (best == nil || a.similarity > bestAlignment.similarity) {
+				best, bestAlignment = family, a
+			}
+		}
+		if best == nil {
+			families = append(families, newFamily(sig))
+			continue
+		}
+		best.add(sig, bestAlignment)
+	}
+
+	sort.SliceStable(families, func(i, j int) bool {
+		return families[i].Count > families[j].Count
+	})
+	return families
+}
+
+func newFamily(sig *Signature) *Family {
+	rep := *sig
+	rep.Queries = make([]Query, len(sig.Queries))
+	copy(rep.Queries, sig.Queries)
+	family := &Family{
+		Count:          sig.Count,
+		Representative: &rep,
+	}
+	if sig.Stats != nil {
+		family.Stats = &TxStats{}
+		family.Stats.Merge(sig.Stats)
+	}
+	return family
+}
+
+func (f *Family) add(sig *Signature, a alignment) {
+	f.Count += sig.Count
+	if sig.Stats != nil {
+		if f.Stats == nil {
+			f.Stats = &TxStats{}
+		}
+		f.Stats.Merge(sig.Stats)
+	}
+
+	for repIdx, sigIdx := range a.matched {
+		rep := &f.Representative.Queries[repIdx]
+		if times := sig.Queries[sigIdx].times(); times > max(rep.times(), rep.MaxRepeat) {
+			rep.MaxRepeat = times
+		}
+	}
+
+	variant := Variant{
+		Count:      sig.Count,
+		Similarity: math.Round(a.similarity*100) / 100,
+	}
+	for _, idx := range a.missing {
+		variant.Missing = append(variant.Missing, f.Representative.Queries[idx])
+	}
+	for _, idx := range a.extra {
+		variant.Extra = append(variant.Extra, sig.Queries[idx])
+	}
+	f.Variants = append(f.Variants, variant)
+}
+
+// alignment is the result of matching the queries of two signatures using their longest common subsequence
+type alignment struct {
+	similarity float64
+	// matched maps the index of a query in the first signature to its match in the second
+	matched map[int]int
+	// missing are the unmatched queries of the first signature, extra the unmatched queries of the second
+	missing, extra []int
+}
+
+func align(a, b []Query) alignment {
+	shapesA := make([]string, len(a))
+	for i, q := range a {
+		shapesA[i] = q.shape()
+	}
+	shapesB := make([]string, len(b))
+	for i, q := range b {
+		shapesB[i] = q.shape()
+	}
+
+	// lcs[i][j] is the length of the longest common subsequence of shapesA[i:] and shapesB[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if shapesA[i] == shapesB[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	res := alignment{matched: map[int]int{}}
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case shapesA[i] == shapesB[j]:
+			res.matched[i] = j
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			res.missing = append(res.missing, i)
+			i++
+		default:
+			res.extra = append(res.extra, j)
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		res.missing = append(res.missing, i)
+	}
+	for ; j < len(b); j++ {
+		res.extra = append(res.extra, j)
+	}
+
+	if total := len(a) + len(b); total > 0 {
+		res.similarity = float64(2*len(res.matched)) / float64(total)
+	}
+	return res
+}
@@ -0,0 +1,102 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package transactions
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCollapseRepeats(t *testing.T) {
+	line := Query{Op: "insert", AffectedTable: "order_line", Assignments: []Assignment{{Col: "order_id", Op: AssignOp, Val: 0}}}
+	sig := &Signature{Queries: []Query{
+		{Op: "insert", AffectedTable: "orders", Assignments: []Assignment{{Col: "id", Op: AssignOp, Val: 0}}},
+		line, line, line,
+		{Op: "update", AffectedTable: "customer"},
+	}}
+
+	collapsed := sig.collapseRepeats()
+	require.Len(t, collapsed.Queries, 3)
+	assert.Equal(t, 3, collapsed.Queries[1].Repeat)
+	assert.Zero(t, collapsed.Queries[0].Repeat)
+}
+
+func TestCollapseRepeatsBeforeCleanUp(t *testing.T) {
+	// every order line has its own id, and all of them point to the order
+	line := func(id int) Query {
+		return Query{Op: "insert", AffectedTable: "order_line", Assignments: []Assignment{
+			{Col: "order_id", Op: AssignOp, Val: 1},
+			{Col: "id", Op: AssignOp, Val: id},
+		}}
+	}
+	sig := &Signature{Queries: []Query{
+		{Op: "insert", AffectedTable: "orders", Assignments: []Assignment{{Col: "id", Op: AssignOp, Val: 1}}},
+		line(2), line(3), line(4),
+	}}
+
+	cleaned := sig.collapseRepeats().CleanUp()
+	require.Len(t, cleaned.Queries, 2)
+	assert.Equal(t, 3, cleaned.Queries[1].Repeat)
+	assert.Equal(t, 0, cleaned.Queries[0].Assignments[0].Val)
+	assert.Equal(t, []Assignment{
+		{Col: "order_id", Op: AssignOp, Val: 0},
+		{Col: "id", Op: AssignOp, Val: -1},
+	}, cleaned.Queries[1].Assignments)
+}
+
+func TestValidateClusterSimilarity(t *testing.T) {
+	require.NoError(t, ValidateClusterSimilarity(DefaultClusterSimilarity))
+	require.NoError(t, ValidateClusterSimilarity(1))
+	require.ErrorContains(t, ValidateClusterSimilarity(0), "invalid cluster similarity 0")
+	require.ErrorContains(t, ValidateClusterSimilarity(-0.5), "invalid cluster similarity -0.5")
+	require.ErrorContains(t, ValidateClusterSimilarity(1.5), "invalid cluster similarity 1.5")
+}
+
+func TestClusterSignatures(t *testing.T) {
+	orders := Query{Op: "insert", AffectedTable: "orders"}
+	lines := func(n int) Query {
+		return Query{Op: "insert", AffectedTable: "order_line", Repeat: n}
+	}
+	customer := Query{Op: "update", AffectedTable: "customer", UpdatedColumns: []string{"balance"}}
+	cart := Query{Op: "delete", AffectedTable: "cart"}
+
+	families := clusterSignatures([]*Signature{
+		{Count: 2, Queries: []Query{orders, lines(5), customer}},
+		{Count: 1, Queries: []Query{orders, lines(2)}},
+		{Count: 5, Queries: []Query{orders, lines(3), customer}},
+		{Count: 3, Queries: []Query{cart}},
+	}, DefaultClusterSimilarity)
+
+	require.Len(t, families, 2)
+	family := families[0]
+	assert.Equal(t, 8, family.Count)
+	assert.Equal(t, 5, family.Representative.Count)
+	assert.Equal(t, 3, family.Representative.Queries[1].Repeat)
+	assert.Equal(t, 5, family.Representative.Queries[1].MaxRepeat)
+
+	require.Len(t, family.Variants, 2)
+	assert.InDelta(t, 1.0, family.Variants[0].Similarity, 0.001)
+	assert.Empty(t, family.Variants[0].Missing)
+	assert.InDelta(t, 0.8, family.Variants[1].Similarity, 0.001)
+	assert.Equal(t, []Query{customer}, family.Variants[1].Missing)
+	assert.Empty(t, family.Variants[1].Extra)
+
+	assert.Equal(t, 3, families[1].Count)
+	assert.Empty(t, families[1].Variants)
+}
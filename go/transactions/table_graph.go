@@ -0,0 +1,143 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package transactions
+
+import (
+	"slices"
+	"sort"
+)
+
+type (
+	// TableEdge connects two tables that are modified in the same transactions.
+	// Tables that are often modified together are candidates for sharing a keyspace and a sharding key.
+	TableEdge struct {
+		Table1 string `json:"table1"`
+		Table2 string `json:"table2"`
+		// Count is the number of transactions that touch both tables
+		Count int `json:"count"`
+		// Columns are the columns of the two tables that were seen with the same value in a transaction
+		Columns []ColumnLink `json:"columns,omitempty"`
+	}
+
+	// ColumnLink is a column of Table1 and a column of Table2 that share values inside transactions
+	ColumnLink struct {
+		Col1  string `json:"col1"`
+		Col2  string `json:"col2"`
+		Count int    `json:"count"`
+	}
+
+	tablePair struct {
+		t1, t2 string
+	}
+
+	columnPair struct {
+		tablePair
+		c1, c2 string
+	}
+
+	tableColumn struct {
+		table, col string
+	}
+)
+
+func newTablePair(a, b string) tablePair {
+	if a < b {
+		return tablePair{t1: a, t2: b}
+	}
+	return tablePair{t1: b, t2: a}
+}
+
+// buildTableGraph builds the co-modification graph of all the tables in the signatures.
+// The signatures should be cleaned up, so values used only once are not counted as correlations.
+func buildTableGraph(signatures []*Signature) []TableEdge {
+	edges := map[tablePair]int{}
+	links := map[columnPair]int{}
+	for _, sig := range signatures {
+		var tables []string
+		values := map[int][]tableColumn{}
+		for _, q := range sig.Queries {
+			if !slices.Contains(tables, q.AffectedTable) {
+				tables = append(tables, q.AffectedTable)
+			}
+			for _, pred := range q.Predicates {
+				if pred.Val >= 0 {
+					values[pred.Val] = append(values[pred.Val], tableColumn{table: q.AffectedTable, col: pred.Col})
+				}
+			}
+			for _, assignment := range q.Assignments {
+				if assignment.Val >= 0 {
+					values[assignment.Val] = append(values[assignment.Val], tableColumn{table: q.AffectedTable, col: assignment.Col})
+				}
+			}
+		}
+
+		for i, a := range tables {
+			for _, b := range tables[i+1:] {
+				edges[newTablePair(a, b)] += sig.Count
+			}
+		}
+
+		// a column pair is only counted once per transaction, even if it shares several values
+		seen := map[columnPair]bool{}
+		for _, cols := range values {
+			for i, a := range cols {
+				for _, b := range cols[i+1:] {
+					if a.table == b.table {
+						continue
+					}
+					if b.table < a.table {
+						a, b = b, a
+					}
+					key := columnPair{tablePair: tablePair{t1: a.table, t2: b.table}, c1: a.col, c2: b.col}
+					if !seen[key] {
+						seen[key] = true
+						links[key] += sig.Count
+					}
+				}
+			}
+		}
+	}
+
+	result := make([]TableEdge, 0, len(edges))
+	for pair, count := range edges {
+		edge := TableEdge{Table1: pair.t1, Table2: pair.t2, Count: count}
+		for key, linkCount := range links {
+			if key.tablePair == pair {
+				edge.Columns = append(edge.Columns, ColumnLink{Col1: key.c1, Col2: key.c2, Count: linkCount})
+			}
+		}
+		sort.Slice(edge.Columns, func(i, j int) bool {
+			a, b := edge.Columns[i], edge.Columns[j]
+			if a.Count != b.Count {
+				return a.Count > b.Count
+			}
+			return a.Col1+"."+a.Col2 < b.Col1+"."+b.Col2
+		})
+		result = append(result, edge)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		a, b := result[i], result[j]
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		}
+		if a.Table1 != b.Table1 {
+			return a.Table1 < b.Table1
+		}
+		return a.Table2 < b.Table2
+	})
+	return result
+}
@@ -0,0 +1,41 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package transactions
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildTableGraph(t *testing.T) {
+	orders := Query{Op: "insert", AffectedTable: "orders", Assignments: []Assignment{{Col: "id", Op: AssignOp, Val: 0}}}
+	lines := Query{Op: "insert", AffectedTable: "order_line", Assignments: []Assignment{{Col: "order_id", Op: AssignOp, Val: 0}}}
+	customer := Query{Op: "update", AffectedTable: "customer", Predicates: []PredicateInfo{{Table: "customer", Col: "id", Val: -1}}}
+
+	graph := buildTableGraph([]*Signature{
+		{Count: 5, Queries: []Query{orders, lines, customer}},
+		{Count: 2, Queries: []Query{orders, lines}},
+		{Count: 3, Queries: []Query{customer}},
+	})
+
+	assert.Equal(t, []TableEdge{
+		{Table1: "order_line", Table2: "orders", Count: 7, Columns: []ColumnLink{{Col1: "order_id", Col2: "id", Count: 7}}},
+		{Table1: "customer", Table2: "order_line", Count: 5},
+		{Table1: "customer", Table2: "orders", Count: 5},
+	}, graph)
+}
@@ -29,8 +29,9 @@ import (
 
 type (
 	Signature struct {
-		Count   int     `json:"count"`
-		Queries []Query `json:"queries"`
+		Count   int      `json:"count"`
+		Queries []Query  `json:"queries"`
+		Stats   *TxStats `json:"stats,omitempty"`
 	}
 
 	Query struct {
@@ -38,10 +39,27 @@ type (
 		AffectedTable  string          `json:"affected_table"`
 		UpdatedColumns []string        `json:"updated_columns,omitempty"`
 		Predicates     []PredicateInfo `json:"predicates,omitempty"`
+		Assignments    []Assignment    `json:"assignments,omitempty"`
+
+		// Repeat is the number of times the query was run in a row, when repeated statements are collapsed
+		Repeat int `json:"repeat,omitempty"`
+		// MaxRepeat is only set on family representatives, when other signatures in the family repeat the query more often
+		MaxRepeat int `json:"max_repeat,omitempty"`
 	}
 
 	txSignatureMap struct {
 		data map[uint64][]*Signature
+
+		// minCount is the minimum number of times a signature has to be seen to be reported
+		minCount int
+
+		// clusterSimilarity is the similarity needed to group signatures into a family. Zero disables clustering.
+		clusterSimilarity float64
+
+		// cleaned is set when the signatures are cleaned up before they are added
+		cleaned bool
+
+		autocommit *AutocommitReport
 	}
 
 	PredicateInfo struct {
@@ -50,8 +68,34 @@ type (
 		Op    sqlparser.ComparisonExprOperator `json:"op"`
 		Val   int                              `json:"val"`
 	}
+
+	// Assignment is a value written to a column by an INSERT or UPDATE.
+	// Val is correlated with predicate values, so a value written by one query
+	// and used to filter a later query shows up with the same number.
+	Assignment struct {
+		Col string `json:"col"`
+		Op  string `json:"op"`
+		Val int    `json:"val"`
+	}
 )
 
+const (
+	AssignOp    = "="
+	IncrementOp = "+"
+	DecrementOp = "-"
+)
+
+func (a Assignment) String() string {
+	val := strconv.Itoa(a.Val)
+	if a.Val == -1 {
+		val = "?"
+	}
+	if a.Op == AssignOp {
+		return fmt.Sprintf("%s = %s", a.Col, val)
+	}
+	return fmt.Sprintf("%s = %s %s %s", a.Col, a.Col, a.Op, val)
+}
+
 func (pi PredicateInfo) String() string {
 	val := strconv.Itoa(pi.Val)
 	if pi.Val == -1 {
@@ -85,10 +129,20 @@ func (tx Query) addToHash(hash hash.Hash64) {
 		_, _ = hash.Write([]byte(pred.String()))
 		_, _ = hash.Write([]byte{0})
 	}
+
+	for _, assignment := range tx.Assignments {
+		_, _ = hash.Write([]byte(assignment.String()))
+		_, _ = hash.Write([]byte{0})
+	}
+
+	if tx.Repeat > 1 {
+		_, _ = hash.Write([]byte(strconv.Itoa(tx.Repeat)))
+		_, _ = hash.Write([]byte{0})
+	}
 }
 
 func (tx Query) Equals(other Query) bool {
-	if tx.Op != other.Op {
+	if tx.Op != other.Op || tx.Repeat != other.Repeat || tx.MaxRepeat != other.MaxRepeat {
 		return false
 	}
 	if tx.AffectedTable != other.AffectedTable {
@@ -110,12 +164,21 @@ func (tx Query) Equals(other Query) bool {
 			return false
 		}
 	}
+	if len(tx.Assignments) != len(other.Assignments) {
+		return false
+	}
+	for i := range tx.Assignments {
+		if tx.Assignments[i] != other.Assignments[i] {
+			return false
+		}
+	}
 	return true
 }
 
-func newTxSignatureMap() *txSignatureMap {
+func newTxSignatureMap(minCount int) *txSignatureMap {
 	return &txSignatureMap{
-		data: make(map[uint64][]*Signature),
+		data:     make(map[uint64][]*Signature),
+		minCount: minCount,
 	}
 }
 
@@ -135,6 +198,7 @@ func (m *txSignatureMap) Add(tx *Signature) {
 	for _, existingTx := range bucket {
 		if tx.Equals(existingTx) {
 			existingTx.Count++
+			existingTx.addStats(tx.Stats)
 			return
 		}
 	}
@@ -143,6 +207,13 @@ func (m *txSignatureMap) Add(tx *Signature) {
 	m.data[hash] = append(bucket, tx)
 }
 
+func (tx *Signature) addStats(stats *TxStats) {
+	if tx.Stats == nil {
+		tx.Stats = &TxStats{}
+	}
+	tx.Stats.Merge(stats)
+}
+
 func (tx *Signature) Equals(other *Signature) bool {
 	if len(tx.Queries) != len(other.Queries) {
 		return false
@@ -158,57 +229,82 @@ func (tx *Signature) Equals(other *Signature) bool {
 
 // CleanUp removes values that are only used once and replaces them with -1
 func (tx *Signature) CleanUp() *Signature {
-	usedValues := make(map[int]int)
-
 	// First let's count how many times each value is used
-	for _, query := range tx.Queries {
-		for _, predicate := range query.Predicates {
-			usedValues[predicate.Val]++
-		}
-	}
+	usedValues := tx.valueUses()
 
 	// Now we replace values only used once with -1
 	newCount := 0
 	newValues := make(map[int]int)
+	renumber := func(val int) int {
+		if val < 0 || usedValues[val] == 1 {
+			return -1
+		}
+		newVal, found := newValues[val]
+		if !found {
+			// Assign a new number to this value
+			newVal = newCount
+			newCount++
+			newValues[val] = newVal
+		}
+		return newVal
+	}
+
 	newQueries := make([]Query, 0, len(tx.Queries))
 	for _, query := range tx.Queries {
 		newPredicates := make([]PredicateInfo, 0, len(query.Predicates))
 		for _, predicate := range query.Predicates {
-			if usedValues[predicate.Val] == 1 {
-				predicate.Val = -1
-			} else {
-				newVal, found := newValues[predicate.Val]
-				if !found {
-					// Assign a new value to this predicate
-					newVal = newCount
-					newCount++
-					newValues[predicate.Val] = newVal
-				}
-				predicate.Val = newVal
-			}
+			predicate.Val = renumber(predicate.Val)
 			newPredicates = append(newPredicates, predicate)
 		}
+		var newAssignments []Assignment
+		for _, assignment := range query.Assignments {
+			assignment.Val = renumber(assignment.Val)
+			newAssignments = append(newAssignments, assignment)
+		}
 		newQueries = append(newQueries, Query{
 			Op:             query.Op,
 			AffectedTable:  query.AffectedTable,
 			UpdatedColumns: query.UpdatedColumns,
 			Predicates:     newPredicates,
+			Assignments:    newAssignments,
+			Repeat:         query.Repeat,
+			MaxRepeat:      query.MaxRepeat,
 		})
 	}
 
 	return &Signature{
 		Queries: newQueries,
 		Count:   tx.Count,
+		Stats:   tx.Stats,
 	}
 }
 
+// valueUses counts how many times each value is used by the queries of the signature
+func (tx *Signature) valueUses() map[int]int {
+	usedValues := make(map[int]int)
+	for _, query := range tx.Queries {
+		for _, predicate := range query.Predicates {
+			usedValues[predicate.Val]++
+		}
+		for _, assignment := range query.Assignments {
+			usedValues[assignment.Val]++
+		}
+	}
+	return usedValues
+}
+
 func (m *txSignatureMap) MarshalJSON() ([]byte, error) {
 	// Collect all interesting TxSignatures into a slice
-	var signatures []*Signature
+	var signatures, all []*Signature
 	for _, bucket := range m.data {
 		for _, txSig := range bucket {
-			if txSig.Count > 1 {
-				signatures = append(signatures, txSig.CleanUp())
+			cleaned := txSig
+			if !m.cleaned {
+				cleaned = txSig.CleanUp()
+			}
+			all = append(all, cleaned)
+			if txSig.Count >= m.minCount {
+				signatures = append(signatures, cleaned)
 			}
 		}
 	}
@@ -218,8 +314,22 @@ func (m *txSignatureMap) MarshalJSON() ([]byte, error) {
 	})
 
 	result := map[string]any{
-		"fileType":   "transactions",
-		"signatures": signatures,
+		"fileType":    "transactions",
+		"signatures":  signatures,
+		"table_graph": buildTableGraph(all),
+	}
+	if m.clusterSimilarity > 0 {
+		// rare signatures are clustered as well, since they are often variations of a common one
+		var families []*Family
+		for _, family := range clusterSignatures(all, m.clusterSimilarity) {
+			if family.Count >= m.minCount {
+				families = append(families, family)
+			}
+		}
+		result["families"] = families
+	}
+	if m.autocommit != nil {
+		result["autocommit"] = m.autocommit
 	}
 
 	return json.Marshal(result)
@@ -0,0 +1,94 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package transactions
+
+import (
+	"math"
+)
+
+// TxStats contains timing and size statistics for all the transactions that share a signature.
+// All times are in seconds.
+type TxStats struct {
+	// TotalDuration is the sum of the wall-clock time from the start of each transaction until the end of its COMMIT
+	TotalDuration float64 `json:"total_duration"`
+	MaxDuration   float64 `json:"max_duration"`
+
+	// TotalQueryTime is the time spent executing queries inside the transactions
+	TotalQueryTime float64 `json:"total_query_time"`
+	TotalLockTime  float64 `json:"total_lock_time"`
+	MaxLockTime    float64 `json:"max_lock_time"`
+
+	// TotalIdleTime is the time the transactions were open without executing any query
+	TotalIdleTime float64 `json:"total_idle_time"`
+	MaxIdleTime   float64 `json:"max_idle_time"`
+
+	// StatementCounts maps the number of INSERT, UPDATE and DELETE statements in a transaction
+	// to how many transactions had that many statements
+	StatementCounts map[int]int `json:"statement_counts"`
+}
+
+func newTxStats(duration, queryTime, lockTime float64, statements int) *TxStats {
+	idle := max(duration-queryTime, 0)
+	return &TxStats{
+		TotalDuration:   roundMicros(duration),
+		MaxDuration:     roundMicros(duration),
+		TotalQueryTime:  roundMicros(queryTime),
+		TotalLockTime:   roundMicros(lockTime),
+		MaxLockTime:     roundMicros(lockTime),
+		TotalIdleTime:   roundMicros(idle),
+		MaxIdleTime:     roundMicros(idle),
+		StatementCounts: map[int]int{statements: 1},
+	}
+}
+
+// Merge adds the statistics of other to the receiver
+func (ts *TxStats) Merge(other *TxStats) {
+	if other == nil {
+		return
+	}
+	ts.TotalDuration = roundMicros(ts.TotalDuration + other.TotalDuration)
+	ts.MaxDuration = max(ts.MaxDuration, other.MaxDuration)
+	ts.TotalQueryTime = roundMicros(ts.TotalQueryTime + other.TotalQueryTime)
+	ts.TotalLockTime = roundMicros(ts.TotalLockTime + other.TotalLockTime)
+	ts.MaxLockTime = max(ts.MaxLockTime, other.MaxLockTime)
+	ts.TotalIdleTime = roundMicros(ts.TotalIdleTime + other.TotalIdleTime)
+	ts.MaxIdleTime = max(ts.MaxIdleTime, other.MaxIdleTime)
+	if ts.StatementCounts == nil {
+		ts.StatementCounts = make(map[int]int, len(other.StatementCounts))
+	}
+	for statements, count := range other.StatementCounts {
+		ts.StatementCounts[statements] += count
+	}
+}
+
+// AvgStatements returns the average number of statements per transaction
+func (ts *TxStats) AvgStatements() float64 {
+	var total, txs int
+	for statements, count := range ts.StatementCounts {
+		total += statements * count
+		txs += count
+	}
+	if txs == 0 {
+		return 0
+	}
+	return float64(total) / float64(txs)
+}
+
+// roundMicros rounds to the precision of the slow query log, so we don't accumulate floating point noise
+func roundMicros(f float64) float64 {
+	return math.Round(f*1e6) / 1e6
+}
@@ -21,9 +21,10 @@ import (
 	"fmt"
 	"io"
 	"os"
-	"strings"
 	"sync"
 
+	"vitess.io/vitess/go/sqltypes"
+	querypb "vitess.io/vitess/go/vt/proto/query"
 	"vitess.io/vitess/go/vt/sqlparser"
 	"vitess.io/vitess/go/vt/vtgate/semantics"
 
@@ -35,12 +36,40 @@ type (
 	Config struct {
 		FileName string
 		Loader   data.Loader
+
+		// MinCount is the minimum number of times a transaction pattern has to be seen to be reported
+		MinCount int
+
+		// Autocommit is the autocommit setting connections start with: on, off or auto.
+		// With auto, the setting is guessed from the start of the log.
+		Autocommit string
+
+		// Cluster collapses repeated statements and groups similar signatures into families.
+		// ClusterSimilarity is how similar, between 0 and 1, a signature has to be to a family to join it.
+		Cluster           bool
+		ClusterSimilarity float64
 	}
 
 	Connection struct {
 		Transaction []sqlparser.Statement
 
 		Autocommit bool
+
+		// ranWithAutocommit and ranWithoutAutocommit record which modes statements on this connection ran with
+		ranWithAutocommit, ranWithoutAutocommit bool
+
+		// These fields track the timing of the transaction currently open on this connection
+		inTx bool
+		// start is the second the transaction started in, startMicros its start in microseconds when the log has them
+		start, startMicros  int64
+		queryTime, lockTime float64
+		statements          int
+	}
+
+	// transaction is a finished transaction, together with the statistics gathered while it was running
+	transaction struct {
+		statements []sqlparser.Statement
+		stats      *TxStats
 	}
 
 	state struct {
@@ -48,6 +77,9 @@ type (
 		si     *keys.SchemaInfo
 		mu     sync.Mutex
 		txs    *txSignatureMap
+
+		// collapse merges repeated statements before signatures are grouped
+		collapse bool
 	}
 )
 
@@ -55,26 +87,17 @@ func Run(cfg Config) {
 	s := &state{
 		parser: sqlparser.NewTestParser(),
 		si:     &keys.SchemaInfo{},
-		txs:    newTxSignatureMap(),
+		txs:    newTxSignatureMap(cfg.MinCount),
 	}
-	s.run(os.Stdout, cfg)
-}
-
-func getAutocommitStatus(set *sqlparser.Set, oldState bool) bool {
-	for _, expr := range set.Exprs {
-		if expr.Var.Name.Lowered() == "autocommit" {
-			val, ok := expr.Expr.(*sqlparser.Literal)
-			if !ok {
-				continue
-			}
-			str := strings.ToLower(val.Val)
-			if str == "1" || str == "on" || str == "true" {
-				return true
-			}
-			return false
+	if cfg.Cluster {
+		s.collapse = true
+		s.txs.clusterSimilarity = cfg.ClusterSimilarity
+		s.txs.cleaned = true
+		if s.txs.clusterSimilarity <= 0 {
+			s.txs.clusterSimilarity = DefaultClusterSimilarity
 		}
 	}
-	return oldState
+	s.run(os.Stdout, cfg)
 }
 
 func (s *state) parse(q string) sqlparser.Statement {
@@ -85,47 +108,188 @@ func (s *state) parse(q string) sqlparser.Statement {
 	return stmt
 }
 
-func (s *state) startProducing(loader data.IteratorLoader, defaultAutocommit bool, ch chan<- []sqlparser.Statement) {
-	connections := map[int]*Connection{}
-	getConn := func(id int) *Connection {
-		connection, ok := connections[id]
-		if !ok {
-			connection = &Connection{Autocommit: defaultAutocommit}
-			connections[id] = connection
-		}
-		return connection
+// sessions keeps track of the state of each connection in the log
+type sessions struct {
+	connections       map[int]*Connection
+	defaultAutocommit bool
+	report            *AutocommitReport
+}
+
+func (ss *sessions) get(id int) *Connection {
+	connection, ok := ss.connections[id]
+	if !ok {
+		connection = &Connection{Autocommit: ss.defaultAutocommit}
+		ss.connections[id] = connection
 	}
-	_ = data.ForeachSQLQuery(loader, func(query data.Query) error {
+	return connection
+}
+
+// end closes the session of a connection. An open transaction is rolled back, like MySQL does when a client disconnects.
+func (ss *sessions) end(id int) {
+	connection, ok := ss.connections[id]
+	if !ok {
+		return
+	}
+	ss.report.addConnection(connection)
+	delete(ss.connections, id)
+}
+
+// close ends all the sessions still open at the end of the log
+func (ss *sessions) close() {
+	for id := range ss.connections {
+		ss.end(id)
+	}
+}
+
+func (s *state) startProducing(loader data.IteratorLoader, ss *sessions, ch chan<- transaction) {
+	_ = data.ForeachSQLQueryWithEvents(loader, func(query data.Query) error {
+		if query.Type == data.ConnectionEvent {
+			// Connect, Quit and Change user all start from a fresh session
+			ss.end(query.ConnectionID)
+			return nil
+		}
 		stmt := s.parse(query.Query)
 		if stmt == nil {
 			return nil
 		}
+		stmt = bindValues(stmt, query.BindVars)
+		connection := ss.get(query.ConnectionID)
 		switch stmt := stmt.(type) {
 		case *sqlparser.Begin:
+			// BEGIN starts a transaction, no matter the autocommit setting
+			if connection.Transaction != nil {
+				// an open transaction is implicitly committed
+				ch <- connection.finish(query)
+			}
+			connection.open(query)
+			connection.track(query, false)
 		case *sqlparser.Commit:
 			// Commit seen, so we can yield the queries in the transaction
-			connection := getConn(query.ConnectionID)
+			connection.track(query, false)
 			if connection.Transaction == nil {
+				connection.reset()
 				return nil
 			}
-			ch <- connection.Transaction
-			connection.Transaction = nil
+			ch <- connection.finish(query)
+		case *sqlparser.Rollback:
+			// the changes were undone, so the transaction is not part of the patterns
+			connection.reset()
 		case *sqlparser.Set:
-			conn := getConn(query.ConnectionID)
-			conn.Autocommit = getAutocommitStatus(stmt, defaultAutocommit)
-		default:
-			if !sqlparser.IsDMLStatement(stmt) {
-				return nil
-			}
-			connection := getConn(query.ConnectionID)
-			if connection.Autocommit {
-				ch <- []sqlparser.Statement{stmt}
-			} else {
-				connection.Transaction = append(connection.Transaction, stmt)
+			autocommit := getAutocommitStatus(stmt, connection.Autocommit)
+			if autocommit && !connection.Autocommit && connection.Transaction != nil {
+				// enabling autocommit commits the open transaction
+				ch <- connection.finish(query)
 			}
+			connection.Autocommit = autocommit
+		default:
+			s.produceStatement(connection, query, stmt, ch)
 		}
 		return nil
 	})
+	ss.close()
+}
+
+func (s *state) produceStatement(connection *Connection, query data.Query, stmt sqlparser.Statement, ch chan<- transaction) {
+	if !sqlparser.IsDMLStatement(stmt) {
+		// not interesting for the signature, but it still adds to the time spent in the transaction
+		connection.track(query, false)
+		return
+	}
+	if connection.Autocommit {
+		connection.ranWithAutocommit = true
+	} else {
+		connection.ranWithoutAutocommit = true
+	}
+	if connection.Autocommit && !connection.inTx {
+		ch <- transaction{
+			statements: []sqlparser.Statement{stmt},
+			stats:      newTxStats(query.QueryTime, query.QueryTime, query.LockTime, 1),
+		}
+		return
+	}
+	connection.open(query)
+	connection.track(query, true)
+	connection.Transaction = append(connection.Transaction, stmt)
+}
+
+// open starts tracking a transaction on the connection, unless one is already open
+func (c *Connection) open(q data.Query) {
+	if c.inTx {
+		return
+	}
+	c.inTx = true
+	c.start = q.Timestamp
+	c.startMicros = 0
+	if q.TimeMicros > 0 {
+		// the query is logged when it ends
+		c.startMicros = q.TimeMicros - int64(q.QueryTime*1e6)
+	}
+	c.queryTime, c.lockTime, c.statements = 0, 0, 0
+}
+
+// track adds the timing of a query to the open transaction, if there is one
+func (c *Connection) track(q data.Query, isStatement bool) {
+	if !c.inTx {
+		return
+	}
+	c.queryTime += q.QueryTime
+	c.lockTime += q.LockTime
+	if isStatement {
+		c.statements++
+	}
+}
+
+// finish closes the open transaction and returns it together with its statistics.
+// The wall-clock duration is measured from the start of the first query to the end of the COMMIT.
+// If the log does not contain timestamps, the duration is the sum of the query times.
+// Timestamps in whole seconds are only used when they are more than a second apart,
+// a transaction crossing a second boundary would otherwise take up to a second longer than it did.
+func (c *Connection) finish(commit data.Query) transaction {
+	duration := c.queryTime
+	switch {
+	case c.startMicros > 0 && commit.TimeMicros > 0:
+		duration = max(duration, float64(commit.TimeMicros-c.startMicros)/1e6)
+	case c.start > 0 && commit.Timestamp > c.start+1:
+		duration = max(duration, float64(commit.Timestamp-c.start)+commit.QueryTime)
+	}
+	tx := transaction{
+		statements: c.Transaction,
+		stats:      newTxStats(duration, c.queryTime, c.lockTime, c.statements),
+	}
+	c.reset()
+	return tx
+}
+
+func (c *Connection) reset() {
+	c.Transaction = nil
+	c.inTx = false
+	c.start, c.startMicros = 0, 0
+	c.queryTime, c.lockTime, c.statements = 0, 0, 0
+}
+
+// bindValues replaces the arguments in the statement with the values they were logged with,
+// so that values can be correlated across queries even when the log is normalized
+func bindValues(stmt sqlparser.Statement, bvs map[string]*querypb.BindVariable) sqlparser.Statement {
+	if len(bvs) == 0 {
+		return stmt
+	}
+	res := sqlparser.Rewrite(stmt, func(cursor *sqlparser.Cursor) bool {
+		arg, ok := cursor.Node().(*sqlparser.Argument)
+		if !ok {
+			return true
+		}
+		bv, found := bvs[arg.Name]
+		if !found {
+			return true
+		}
+		if sqltypes.IsIntegral(bv.Type) {
+			cursor.Replace(sqlparser.NewIntLiteral(string(bv.Value)))
+		} else {
+			cursor.Replace(sqlparser.NewStrLiteral(string(bv.Value)))
+		}
+		return true
+	}, nil)
+	return res.(sqlparser.Statement) //nolint:errcheck // rewriting a statement always returns a statement
 }
 
 func exprToString(expr sqlparser.Expr) string {
@@ -135,12 +299,29 @@ func exprToString(expr sqlparser.Expr) string {
 	return ""
 }
 
+// exprToStrings returns the values of a literal or of all the literals in a tuple
+func exprToStrings(expr sqlparser.Expr) []string {
+	tuple, ok := expr.(sqlparser.ValTuple)
+	if !ok {
+		if str := exprToString(expr); str != "" {
+			return []string{str}
+		}
+		return nil
+	}
+	var values []string
+	for _, e := range tuple {
+		if str := exprToString(e); str != "" {
+			values = append(values, str)
+		}
+	}
+	return values
+}
+
 func createPredicateInfo(
 	st *semantics.SemTable,
 	expr *sqlparser.ColName,
 	op sqlparser.ComparisonExprOperator,
-	value string,
-	n *normalizer,
+	value int,
 ) PredicateInfo {
 	tableInfo, err := st.TableInfoForExpr(expr)
 	if err != nil {
@@ -154,7 +335,7 @@ func createPredicateInfo(
 		Table: table.Name.String(),
 		Col:   expr.Name.String(),
 		Op:    op,
-		Val:   n.normalize(value),
+		Val:   value,
 	}
 }
 
@@ -174,6 +355,28 @@ func (n *normalizer) normalize(s string) int {
 	return id
 }
 
+// normalizeAll gives a group of values, such as the members of an IN list, a single id.
+// If any of the values has been seen before, the group reuses its id, so it correlates with the earlier use.
+func (n *normalizer) normalizeAll(values []string) int {
+	id := -1
+	for _, v := range values {
+		if existing, ok := n.m[v]; ok {
+			id = existing
+			break
+		}
+	}
+	if id == -1 {
+		id = n.next
+		n.next++
+	}
+	for _, v := range values {
+		if _, ok := n.m[v]; !ok {
+			n.m[v] = id
+		}
+	}
+	return id
+}
+
 func getPredicates(e sqlparser.Expr, st *semantics.SemTable, n *normalizer) (predicates []PredicateInfo) {
 	// TODO: Implement support for join predicates
 	for _, predicate := range sqlparser.SplitAndExpression(nil, e) {
@@ -185,14 +388,21 @@ func getPredicates(e sqlparser.Expr, st *semantics.SemTable, n *normalizer) (pre
 		lhs, lhsOK := cmp.Left.(*sqlparser.ColName)
 		rhs, rhsOK := cmp.Right.(*sqlparser.ColName)
 
+		if cmp.Operator == sqlparser.InOp {
+			if values := exprToStrings(cmp.Right); lhsOK && len(values) > 0 {
+				predicates = append(predicates, createPredicateInfo(st, lhs, cmp.Operator, n.normalizeAll(values)))
+			}
+			continue
+		}
+
 		if rhsStr := exprToString(cmp.Right); lhsOK && rhsStr != "" {
-			predicates = append(predicates, createPredicateInfo(st, lhs, cmp.Operator, rhsStr, n))
+			predicates = append(predicates, createPredicateInfo(st, lhs, cmp.Operator, n.normalize(rhsStr)))
 		}
 
 		if lhsStr := exprToString(cmp.Left); rhsOK && lhsStr != "" {
 			switchedOp, ok := cmp.Operator.SwitchSides()
 			if ok {
-				predicates = append(predicates, createPredicateInfo(st, rhs, switchedOp, lhsStr, n))
+				predicates = append(predicates, createPredicateInfo(st, rhs, switchedOp, n.normalize(lhsStr)))
 			}
 		}
 	}
@@ -200,24 +410,59 @@ func getPredicates(e sqlparser.Expr, st *semantics.SemTable, n *normalizer) (pre
 	return
 }
 
-func (s *state) consume(ch <-chan []sqlparser.Statement, wg *sync.WaitGroup) {
+// getAssignment returns the value assigned to a column in the SET clause of an UPDATE,
+// for plain literals and for `col = col + N` and `col = col - N`.
+// The N of an increment or decrement is a delta, not a value of the column, so it is not correlated with other values.
+func getAssignment(expr *sqlparser.UpdateExpr, n *normalizer) (Assignment, bool) {
+	col := expr.Name.Name.String()
+	if str := exprToString(expr.Expr); str != "" {
+		return Assignment{Col: col, Op: AssignOp, Val: n.normalize(str)}, true
+	}
+
+	bin, ok := expr.Expr.(*sqlparser.BinaryExpr)
+	if !ok {
+		return Assignment{}, false
+	}
+	lhs, ok := bin.Left.(*sqlparser.ColName)
+	if !ok || !lhs.Name.Equal(expr.Name.Name) {
+		return Assignment{}, false
+	}
+	if exprToString(bin.Right) == "" {
+		return Assignment{}, false
+	}
+	switch bin.Operator {
+	case sqlparser.PlusOp:
+		return Assignment{Col: col, Op: IncrementOp, Val: -1}, true
+	case sqlparser.MinusOp:
+		return Assignment{Col: col, Op: DecrementOp, Val: -1}, true
+	default:
+		return Assignment{}, false
+	}
+}
+
+func (s *state) consume(ch <-chan transaction, wg *sync.WaitGroup) {
 	defer wg.Done()
-	for queries := range ch {
+	for t := range ch {
 		n := &normalizer{m: make(map[string]int)}
-		tx := &Signature{}
-		for _, query := range queries {
+		tx := &Signature{Stats: t.stats}
+		for _, query := range t.statements {
 			st, err := semantics.Analyze(query, "ks", s.si)
 			if err != nil {
 				panic(err)
 			}
 
 			switch query := query.(type) {
+			case *sqlparser.Insert:
+				s.consumeInsert(query, n, tx)
 			case *sqlparser.Update:
 				s.consumeUpdate(query, st, n, tx)
 			case *sqlparser.Delete:
 				s.consumeDelete(query, st, n, tx)
 			}
 		}
+		if s.collapse {
+			tx = tx.collapseRepeats().CleanUp()
+		}
 		s.addSignature(tx)
 	}
 }
@@ -230,8 +475,12 @@ func (s *state) consumeUpdate(query *sqlparser.Update, st *semantics.SemTable, n
 	}
 
 	updatedColumns := make([]string, 0, len(query.Exprs))
+	var assignments []Assignment
 	for _, expr := range query.Exprs {
 		updatedColumns = append(updatedColumns, sqlparser.String(expr.Name.Name))
+		if assignment, ok := getAssignment(expr, n); ok {
+			assignments = append(assignments, assignment)
+		}
 	}
 
 	if len(query.TableExprs) != 1 {
@@ -244,6 +493,40 @@ func (s *state) consumeUpdate(query *sqlparser.Update, st *semantics.SemTable, n
 		AffectedTable:  sqlparser.String(query.TableExprs[0]),
 		UpdatedColumns: updatedColumns,
 		Predicates:     predicates,
+		Assignments:    assignments,
+	})
+}
+
+func (s *state) consumeInsert(ins *sqlparser.Insert, n *normalizer, tx *Signature) {
+	// The values inserted into each column are recorded, so they can be correlated with later predicates.
+	// With multiple rows, all the values of a column are treated as one group, the same way as an IN list.
+	var assignments []Assignment
+	if rows, ok := ins.Rows.(sqlparser.Values); ok {
+		for idx, col := range ins.Columns {
+			var values []string
+			for _, row := range rows {
+				if idx >= len(row) {
+					continue
+				}
+				if str := exprToString(row[idx]); str != "" {
+					values = append(values, str)
+				}
+			}
+			if len(values) == 0 {
+				continue
+			}
+			assignments = append(assignments, Assignment{
+				Col: col.String(),
+				Op:  AssignOp,
+				Val: n.normalizeAll(values),
+			})
+		}
+	}
+
+	tx.Queries = append(tx.Queries, Query{
+		Op:            "insert",
+		AffectedTable: sqlparser.String(ins.Table),
+		Assignments:   assignments,
 	})
 }
 
@@ -272,10 +555,19 @@ func (s *state) addSignature(tx *Signature) {
 }
 
 func (s *state) run(out io.Writer, cfg Config) {
-	defaultAutocommit := s.getAutocommitGuess(cfg)
+	mode := cfg.Autocommit
+	if mode == "" {
+		mode = AutocommitAuto
+	}
+	defaultAutocommit := s.getDefaultAutocommit(cfg)
+	ss := &sessions{
+		connections:       map[int]*Connection{},
+		defaultAutocommit: defaultAutocommit,
+		report:            &AutocommitReport{Mode: mode, Default: defaultAutocommit},
+	}
 
 	loader := cfg.Loader.Load(cfg.FileName)
-	ch := make(chan []sqlparser.Statement, 1000)
+	ch := make(chan transaction, 1000)
 
 	noOfConsumers := 1
 	var wg sync.WaitGroup
@@ -285,58 +577,16 @@ func (s *state) run(out io.Writer, cfg Config) {
 	}
 
 	go func() {
-		s.startProducing(loader, defaultAutocommit, ch)
+		s.startProducing(loader, ss, ch)
 		close(ch)
 	}()
 
 	wg.Wait()
 
+	s.txs.autocommit = ss.report
 	txsJSON, err := json.MarshalIndent(s.txs, "", "  ")
 	if err != nil {
 		panic(err)
 	}
 	_, _ = fmt.Fprintf(out, "%s\n", string(txsJSON))
 }
-
-func (s *state) getAutocommitGuess(cfg Config) bool {
-	// Figure out if autocommit is enabled
-	// If we see:
-	// 1. BEGIN we can assume autocommit is disabled
-	// 2. COMMIT and no BEGIN we can assume autocommit is enabled
-	// 3. ROLLBACK and no BEGIN we can assume autocommit is enabled
-	// 4. SET autocommit = 1/0
-	count := 1000
-	defaultAutocommit := true
-	loader := cfg.Loader.Load(cfg.FileName)
-	defer func() {
-		err := loader.Close()
-		if err != nil {
-			panic(err.Error())
-		}
-	}()
-	_ = data.ForeachSQLQuery(loader, func(query data.Query) error {
-		count--
-		if count == 0 {
-			// enough already. we'll assume autocommit is enabled because that is the default
-			return io.EOF
-		}
-
-		stmt := s.parse(query.Query)
-		if stmt == nil {
-			return nil
-		}
-
-		switch stmt.(type) {
-		case *sqlparser.Begin:
-			// BEGIN seen, so autocommit is disabled
-			return io.EOF
-		case *sqlparser.Commit:
-			defaultAutocommit = false
-			// no BEGIN seen, so autocommit is disabled
-			return io.EOF
-		}
-
-		return nil
-	})
-	return defaultAutocommit
-}
@@ -18,11 +18,15 @@ package transactions
 
 import (
 	"os"
+	"path/filepath"
 	"strings"
+	"sync"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"vitess.io/vitess/go/sqltypes"
+	querypb "vitess.io/vitess/go/vt/proto/query"
 	"vitess.io/vitess/go/vt/sqlparser"
 
 	"github.com/vitessio/vt/go/data"
@@ -34,7 +38,7 @@ func TestRun(t *testing.T) {
 	s := &state{
 		parser: sqlparser.NewTestParser(),
 		si:     &keys.SchemaInfo{},
-		txs:    newTxSignatureMap(),
+		txs:    newTxSignatureMap(2),
 	}
 	s.run(sb, Config{
 		FileName: "../testdata/query-logs/small-slow-query-log",
@@ -80,11 +84,25 @@ func TestAutocommitSettings(t *testing.T) {
 			query:  "set @@session.autocommit=0",
 			expect: false,
 		}, {
+			// global settings don't change the current session
 			query:  "set global autocommit = 1",
-			expect: true,
+			expect: false,
 		}, {
 			query:  "set global autocommit = 0",
 			expect: false,
+		}, {
+			query:  "set @@autocommit = 1",
+			expect: true,
+		}, {
+			query:  "set autocommit = true",
+			expect: true,
+		}, {
+			query:  "set names utf8mb4, autocommit = 1",
+			expect: true,
+		}, {
+			// a user variable, not the system variable
+			query:  "set @autocommit = 1",
+			expect: false,
 		},
 	}
 
@@ -99,3 +117,218 @@ func TestAutocommitSettings(t *testing.T) {
 		})
 	}
 }
+
+func TestTransactionStats(t *testing.T) {
+	conn := &Connection{}
+	conn.open(data.Query{Timestamp: 100})
+	conn.track(data.Query{QueryTime: 0.5, LockTime: 0.1}, false)
+	conn.track(data.Query{QueryTime: 1, LockTime: 0.2}, true)
+	conn.track(data.Query{QueryTime: 0.5}, true)
+	conn.Transaction = []sqlparser.Statement{&sqlparser.Update{}}
+
+	tx := conn.finish(data.Query{Timestamp: 104, QueryTime: 0.5})
+	assert.InDelta(t, 4.5, tx.stats.TotalDuration, 0.000001)
+	assert.InDelta(t, 2.0, tx.stats.TotalQueryTime, 0.000001)
+	assert.InDelta(t, 2.5, tx.stats.TotalIdleTime, 0.000001)
+	assert.InDelta(t, 0.3, tx.stats.TotalLockTime, 0.000001)
+	assert.Equal(t, map[int]int{2: 1}, tx.stats.StatementCounts)
+	assert.False(t, conn.inTx)
+	assert.Nil(t, conn.Transaction)
+
+	tx.stats.Merge(newTxStats(1, 1, 0.2, 4))
+	assert.InDelta(t, 5.5, tx.stats.TotalDuration, 0.000001)
+	assert.InDelta(t, 4.5, tx.stats.MaxDuration, 0.000001)
+	assert.InDelta(t, 0.5, tx.stats.TotalLockTime, 0.000001)
+	assert.InDelta(t, 0.3, tx.stats.MaxLockTime, 0.000001)
+	assert.InDelta(t, 3.0, tx.stats.AvgStatements(), 0.000001)
+}
+
+func TestTransactionDuration(t *testing.T) {
+	// the transaction crosses a second boundary, the microsecond times show it took 0.3s
+	conn := &Connection{}
+	conn.open(data.Query{Timestamp: 100, TimeMicros: 100_900_000, QueryTime: 0.1})
+	conn.track(data.Query{QueryTime: 0.1}, true)
+	conn.track(data.Query{QueryTime: 0.05}, false)
+	tx := conn.finish(data.Query{Timestamp: 101, TimeMicros: 101_100_000, QueryTime: 0.05})
+	assert.InDelta(t, 0.3, tx.stats.TotalDuration, 0.000001)
+	assert.InDelta(t, 0.15, tx.stats.TotalIdleTime, 0.000001)
+
+	// without microseconds, timestamps a second apart could be any time under two seconds apart
+	conn.open(data.Query{Timestamp: 100})
+	conn.track(data.Query{QueryTime: 0.1}, true)
+	tx = conn.finish(data.Query{Timestamp: 101})
+	assert.InDelta(t, 0.1, tx.stats.TotalDuration, 0.000001)
+	assert.Zero(t, tx.stats.TotalIdleTime)
+}
+
+func TestStatementsOnlyCountWrites(t *testing.T) {
+	ch := make(chan transaction, 1)
+	s := &state{parser: sqlparser.NewTestParser()}
+	conn := &Connection{}
+	for _, query := range []string{"update customer set balance = 0 where id = 5", "select * from customer where id = 5"} {
+		s.produceStatement(conn, data.Query{Query: query, QueryTime: 0.1}, s.parse(query), ch)
+	}
+	tx := conn.finish(data.Query{QueryTime: 0.1})
+	assert.Equal(t, map[int]int{1: 1}, tx.stats.StatementCounts)
+	assert.InDelta(t, 0.2, tx.stats.TotalQueryTime, 0.000001)
+}
+
+// consumeSignature runs the queries as one transaction and returns its cleaned up signature
+func consumeSignature(t *testing.T, queries ...string) *Signature {
+	parser := sqlparser.NewTestParser()
+	var stmts []sqlparser.Statement
+	for _, query := range queries {
+		stmt, err := parser.Parse(query)
+		require.NoError(t, err)
+		stmts = append(stmts, bindValues(stmt, map[string]*querypb.BindVariable{"cid": sqltypes.Int64BindVariable(5)}))
+	}
+
+	s := &state{
+		si:  &keys.SchemaInfo{},
+		txs: newTxSignatureMap(1),
+	}
+	ch := make(chan transaction, 1)
+	ch <- transaction{statements: stmts}
+	close(ch)
+	var wg sync.WaitGroup
+	wg.Add(1)
+	s.consume(ch, &wg)
+
+	var sigs []*Signature
+	for _, bucket := range s.txs.data {
+		sigs = append(sigs, bucket...)
+	}
+	require.Len(t, sigs, 1)
+	return sigs[0].CleanUp()
+}
+
+func TestValueCorrelation(t *testing.T) {
+	sig := consumeSignature(t,
+		"insert into orders(id, customer_id) values (10, 5)",
+		"update customer set balance = balance - 20 where id = 5",
+		"update order_line set status = 'done' where order_id in (10, 11)",
+		"delete from cart where customer_id = :cid",
+	)
+	require.Len(t, sig.Queries, 4)
+
+	// the order id and customer id inserted are used by the later queries
+	assert.Equal(t, []string{"id = 0", "customer_id = 1"}, assignmentStrings(sig.Queries[0].Assignments))
+	assert.Equal(t, []string{"balance = balance - ?"}, assignmentStrings(sig.Queries[1].Assignments))
+	assert.Equal(t, "customer.id = 1", sig.Queries[1].Predicates[0].String())
+	assert.Equal(t, "order_line.order_id in 0", sig.Queries[2].Predicates[0].String())
+	assert.Equal(t, "cart.customer_id = 1", sig.Queries[3].Predicates[0].String())
+}
+
+func TestIncrementNotCorrelated(t *testing.T) {
+	sig := consumeSignature(t,
+		"update stock set quantity = quantity + 1 where product_id = 7",
+		"update warehouse set name = 'main' where id = 1",
+	)
+	require.Len(t, sig.Queries, 2)
+
+	// the 1 added to the quantity has nothing to do with the warehouse id
+	assert.Equal(t, []string{"quantity = quantity + ?"}, assignmentStrings(sig.Queries[0].Assignments))
+	assert.Equal(t, "warehouse.id = ?", sig.Queries[1].Predicates[0].String())
+
+	graph := buildTableGraph([]*Signature{sig})
+	require.Len(t, graph, 1)
+	assert.Empty(t, graph[0].Columns)
+}
+
+func assignmentStrings(assignments []Assignment) []string {
+	var res []string
+	for _, a := range assignments {
+		res = append(res, a.String())
+	}
+	return res
+}
+
+func TestAutocommitSessions(t *testing.T) {
+	parser := sqlparser.NewTestParser()
+	queries := []data.Query{
+		{Query: "insert into t(id) values (1)", ConnectionID: 1},
+		{Query: "begin", ConnectionID: 1},
+		{Query: "update t set a = 1 where id = 1", ConnectionID: 1},
+		{Query: "update u set b = 1 where id = 1", ConnectionID: 1},
+		{Query: "commit", ConnectionID: 1},
+		{Query: "set autocommit = 0", ConnectionID: 2},
+		{Query: "update t set a = 2 where id = 2", ConnectionID: 2},
+		{Query: "rollback", ConnectionID: 2},
+		{Query: "update t set a = 3 where id = 3", ConnectionID: 2},
+		{Type: data.ConnectionEvent, FirstWord: data.QuitEvent, ConnectionID: 2},
+		{Query: "update t set a = 4 where id = 4", ConnectionID: 2},
+	}
+	s := &state{parser: parser}
+	ss := &sessions{
+		connections:       map[int]*Connection{},
+		defaultAutocommit: true,
+		report:            &AutocommitReport{},
+	}
+	ch := make(chan transaction, 10)
+	s.startProducing(&sliceLoader{queries: queries}, ss, ch)
+	close(ch)
+
+	var sizes []int
+	for tx := range ch {
+		sizes = append(sizes, len(tx.statements))
+	}
+	// the insert runs on its own, BEGIN groups the two updates, and the
+	// open transaction is dropped when the connection quits
+	assert.Equal(t, []int{1, 2, 1}, sizes)
+	assert.Equal(t, AutocommitReport{On: 2, Off: 1}, *ss.report)
+}
+
+func TestAutocommitSessionsFromQueryLog(t *testing.T) {
+	log := filepath.Join(t.TempDir(), "general.log")
+	err := os.WriteFile(log, []byte(`Time                 Id Command    Argument
+2024-11-06T09:57:35.000000Z	    7 Connect	app@localhost on shop using TCP/IP
+2024-11-06T09:57:35.100000Z	    7 Query	set autocommit = 0
+2024-11-06T09:57:35.200000Z	    7 Query	update t set a = 1 where id = 1
+2024-11-06T09:57:35.300000Z	    7 Change user	admin@localhost on shop
+2024-11-06T09:57:35.400000Z	    7 Query	update t set a = 2 where id = 2
+2024-11-06T09:57:35.500000Z	    7 Query	set autocommit = 0
+2024-11-06T09:57:35.600000Z	    7 Query	update t set a = 3 where id = 3
+2024-11-06T09:57:35.700000Z	    7 Quit	
+2024-11-06T09:57:35.800000Z	    7 Connect	app@localhost on shop using TCP/IP
+2024-11-06T09:57:35.900000Z	    7 Query	update t set a = 4 where id = 4
+`), 0o600)
+	require.NoError(t, err)
+
+	s := &state{parser: sqlparser.NewTestParser()}
+	ss := &sessions{
+		connections:       map[int]*Connection{},
+		defaultAutocommit: true,
+		report:            &AutocommitReport{},
+	}
+	ch := make(chan transaction, 10)
+	s.startProducing(data.MySQLLogLoader{ConnectionEvents: true}.Load(log), ss, ch)
+	close(ch)
+
+	var sizes []int
+	for tx := range ch {
+		sizes = append(sizes, len(tx.statements))
+	}
+	// Change user rolls back the transaction autocommit = 0 opened and starts over with autocommit on,
+	// Quit rolls back the second one, and the new connection runs its update with autocommit on again
+	assert.Equal(t, []int{1, 1}, sizes)
+	// three sessions: one with autocommit off, one that turned it off, and one with autocommit on
+	assert.Equal(t, AutocommitReport{On: 1, Off: 1, Mixed: 1}, *ss.report)
+	assert.Empty(t, ss.connections)
+}
+
+type sliceLoader struct {
+	queries []data.Query
+}
+
+func (l *sliceLoader) Next() (data.Query, bool) {
+	if len(l.queries) == 0 {
+		return data.Query{}, false
+	}
+	q := l.queries[0]
+	l.queries = l.queries[1:]
+	return q, true
+}
+
+func (l *sliceLoader) Close() error {
+	return nil
+}